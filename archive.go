@@ -0,0 +1,176 @@
+package geozip
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// gzipMagic are the first bytes of gzip-compressed data.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// archive provides access to the members of a downloaded archive, regardless of its format.
+type archive interface {
+	// names returns the names of the members in the order in which they are stored.
+	names() []string
+	// open opens the first member with the given name. The member is decompressed as it is read.
+	open(name string) (io.ReadCloser, error)
+}
+
+// openArchive detects the format of the archive read from r by its magic bytes and opens it.
+// Besides zip archives, which start with "PK", gzip-compressed data is supported, e.g. from mirrors
+// that offer .tar.gz or .txt.gz files. Gzip-compressed tar archives provide their regular files as members.
+// Other gzip-compressed data is a single member, named after the original file name stored in the gzip header,
+// or defaultName if the header has none. Data in any other format is read as a zip archive.
+func openArchive(r io.ReaderAt, size int64, defaultName string) (archive, error) {
+	magic := make([]byte, len(gzipMagic))
+	if n, _ := r.ReadAt(magic, 0); n == len(magic) && bytes.Equal(magic, gzipMagic) {
+		return openGzip(r, size, defaultName)
+	}
+
+	unzip, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: create unzipping reader: %w", ErrInvalidArchive, err)
+	}
+	return zipArchive{unzip}, nil
+}
+
+// zipArchive is an archive in zip format.
+type zipArchive struct {
+	r *zip.Reader
+}
+
+func (a zipArchive) names() []string {
+	names := make([]string, len(a.r.File))
+	for i, f := range a.r.File {
+		names[i] = f.Name
+	}
+	return names
+}
+
+func (a zipArchive) open(name string) (io.ReadCloser, error) {
+	for _, f := range a.r.File {
+		if f.Name == name {
+			return f.Open()
+		}
+	}
+	return nil, fmt.Errorf("no member %s", name)
+}
+
+// openGzip opens the gzip-compressed data read from r, which is either a tar archive or a single file.
+// A tar archive is recognized by the magic of the ustar header, which is shared by all formats written by
+// common tar implementations.
+func openGzip(r io.ReaderAt, size int64, defaultName string) (archive, error) {
+	gz, err := gzip.NewReader(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return nil, fmt.Errorf("%w: create gzip reader: %w", ErrInvalidArchive, err)
+	}
+	defer gz.Close()
+
+	block := make([]byte, 512)
+	_, err = io.ReadFull(gz, block)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("%w: read gzip data: %w", ErrInvalidArchive, err)
+	}
+	if err == nil && bytes.Equal(block[257:262], []byte("ustar")) {
+		return openTarGzip(r, size)
+	}
+
+	name := gz.Name
+	if name == "" {
+		name = defaultName
+	}
+	return gzipFile{r: r, size: size, name: name}, nil
+}
+
+// gzipFile is a single gzip-compressed file, treated as an archive with one member.
+type gzipFile struct {
+	r    io.ReaderAt
+	size int64
+	name string
+}
+
+func (a gzipFile) names() []string {
+	return []string{a.name}
+}
+
+func (a gzipFile) open(name string) (io.ReadCloser, error) {
+	if name != a.name {
+		return nil, fmt.Errorf("no member %s", name)
+	}
+	return gzip.NewReader(io.NewSectionReader(a.r, 0, a.size))
+}
+
+// tarGzip is a gzip-compressed tar archive. As tar archives have no index, the archive is decompressed
+// once to list its members, and again up to the member whenever a member is opened.
+type tarGzip struct {
+	r       io.ReaderAt
+	size    int64
+	members []string
+}
+
+// openTarGzip lists the regular files of the gzip-compressed tar archive read from r.
+func openTarGzip(r io.ReaderAt, size int64) (archive, error) {
+	a := tarGzip{r: r, size: size}
+	_, _, err := a.scan(func(hdr *tar.Header) bool {
+		a.members = append(a.members, hdr.Name)
+		return false
+	})
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a tarGzip) names() []string {
+	return a.members
+}
+
+func (a tarGzip) open(name string) (io.ReadCloser, error) {
+	gz, tr, err := a.scan(func(hdr *tar.Header) bool {
+		return hdr.Name == name
+	})
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("no member %s", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tarMember{Reader: tr, gz: gz}, nil
+}
+
+// scan reads the archive up to the first regular file for which stop returns true and returns the readers
+// positioned at its content. If stop never returns true, scan fails with io.EOF.
+func (a tarGzip) scan(stop func(*tar.Header) bool) (*gzip.Reader, *tar.Reader, error) {
+	gz, err := gzip.NewReader(io.NewSectionReader(a.r, 0, a.size))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: create gzip reader: %w", ErrInvalidArchive, err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				err = fmt.Errorf("%w: read tar header: %w", ErrInvalidArchive, err)
+			}
+			return nil, nil, errors.Join(err, gz.Close())
+		}
+		if hdr.Typeflag == tar.TypeReg && stop(hdr) {
+			return gz, tr, nil
+		}
+	}
+}
+
+// tarMember is the content of a member of a gzip-compressed tar archive.
+type tarMember struct {
+	*tar.Reader
+	gz *gzip.Reader
+}
+
+func (m tarMember) Close() error {
+	return m.gz.Close()
+}
@@ -0,0 +1,155 @@
+package geozip_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ngrash/geozip"
+)
+
+// gzipData returns content compressed with gzip, storing name as the original file name if it is not empty.
+func gzipData(t *testing.T, name, content string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Name = name
+	if _, err := w.Write([]byte(content)); err != nil {
+		t.Fatal("write gzip data", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal("close gzip writer", err)
+	}
+	return buf.Bytes()
+}
+
+// tarGzipArchive returns a gzip-compressed tar archive with the given members, stored in the order given.
+func tarGzipArchive(t *testing.T, members ...[2]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var tarBuf bytes.Buffer
+	w := tar.NewWriter(&tarBuf)
+	for _, m := range members {
+		hdr := &tar.Header{Name: m[0], Mode: 0o644, Size: int64(len(m[1])), Typeflag: tar.TypeReg}
+		if err := w.WriteHeader(hdr); err != nil {
+			t.Fatal("write tar header", err)
+		}
+		if _, err := w.Write([]byte(m[1])); err != nil {
+			t.Fatal("write tar member", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal("close tar writer", err)
+	}
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(tarBuf.Bytes()); err != nil {
+		t.Fatal("write gzip data", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal("close gzip writer", err)
+	}
+	return buf.Bytes()
+}
+
+func TestFetchCountry_Formats(t *testing.T) {
+	const row = "DE\t54668\tFerschweiler\n"
+	tests := []struct {
+		name       string
+		data       []byte
+		wantMember string
+	}{
+		{"zip", zipArchive(t, map[string]string{"DE.txt": row}), "DE.txt"},
+		{"gzip", gzipData(t, "DE.txt", row), "DE.txt"},
+		{"gzip without name", gzipData(t, "", row), "DE.txt"},
+		{"gzip with other name", gzipData(t, "postal.txt", row), "postal.txt"},
+		{"tar.gz", tarGzipArchive(t, [2]string{"readme.txt", "readme"}, [2]string{"DE.txt", row}), "DE.txt"},
+		{"tar.gz with other name", tarGzipArchive(t, [2]string{"readme.txt", "readme"}, [2]string{"data/DE.txt", row}), "data/DE.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &geozip.Client{HTTPClient: &http.Client{Transport: serveBytes(tt.data, "etag")}}
+			res, err := client.FetchCountryResult("DE", "")
+			if err != nil {
+				t.Fatalf("err = %v, want nil", err)
+			}
+			if len(res.Entries) != 1 || res.Entries[0].Get(geozip.PlaceName) != "Ferschweiler" {
+				t.Errorf("entries = %v, want Ferschweiler", res.Entries)
+			}
+			if res.Member != tt.wantMember {
+				t.Errorf("Member = %q, want %q", res.Member, tt.wantMember)
+			}
+		})
+	}
+}
+
+func TestParseReader_Gzip(t *testing.T) {
+	data := gzipData(t, "DE.txt", "DE\t54668\tFerschweiler\nDE\t54636\tBitburg\n")
+	entries, err := geozip.ParseReader(bytes.NewReader(data), "DE")
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if got, want := len(entries), 2; got != want {
+		t.Errorf("len(entries) = %d, want %d", got, want)
+	}
+}
+
+func TestParseReader_Position(t *testing.T) {
+	// Gzip readers read concatenated streams, so parsing from the start would return both entries.
+	skipped := gzipData(t, "DE.txt", "DE\t54668\tFerschweiler\n")
+	data := append(skipped, gzipData(t, "DE.txt", "DE\t54636\tBitburg\n")...)
+	path := filepath.Join(t.TempDir(), "DE.txt.gz")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	for name, r := range map[string]io.ReadSeeker{"bytes.Reader": bytes.NewReader(data), "os.File": file} {
+		if _, err := r.Seek(int64(len(skipped)), io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+		entries, err := geozip.ParseReader(r, "DE")
+		if err != nil {
+			t.Fatalf("%s: err = %v, want nil", name, err)
+		}
+		if len(entries) != 1 || entries[0].Get(geozip.PlaceName) != "Bitburg" {
+			t.Errorf("%s: entries = %v, want Bitburg", name, entries)
+		}
+	}
+}
+
+func TestFetchMember_TarGzip(t *testing.T) {
+	data := tarGzipArchive(t, [2]string{"DE.txt", "DE\t54668\tFerschweiler\n"}, [2]string{"readme.txt", "license"})
+	client := &geozip.Client{HTTPClient: &http.Client{Transport: serveBytes(data, "etag")}}
+
+	got, _, _, err := client.FetchMember("DE", "", "readme.txt")
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if string(got) != "license" {
+		t.Errorf("readme = %q, want %q", got, "license")
+	}
+
+	_, _, _, err = client.FetchMember("DE", "", "missing.txt")
+	if err == nil || !strings.Contains(err.Error(), "missing.txt") {
+		t.Errorf("err = %v, want error about missing.txt", err)
+	}
+}
+
+func TestFetchCountry_CorruptGzip(t *testing.T) {
+	data := gzipData(t, "DE.txt", "DE\t54668\tFerschweiler\n")
+	data = data[:len(data)-8]
+	client := &geozip.Client{HTTPClient: &http.Client{Transport: serveBytes(data, "etag")}}
+	if _, _, _, err := client.FetchCountry("DE", ""); err == nil {
+		t.Error("err = nil, want error for truncated gzip data")
+	}
+}
@@ -0,0 +1,121 @@
+package geozip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// DefaultConcurrency is the number of countries fetched concurrently by FetchCountries
+// if the client's Concurrency is not set.
+const DefaultConcurrency = 4
+
+// CountryResult is the outcome of fetching a single country with FetchCountries.
+type CountryResult struct {
+	FetchResult
+	// Err is the error that occurred while fetching the country, if any.
+	Err error
+}
+
+// FetchCountries fetches postal code entries for multiple countries concurrently.
+// See the Client method of the same name for details.
+func FetchCountries(ccs []string, etags map[string]string) map[string]CountryResult {
+	return defaultClient.FetchCountries(ccs, etags)
+}
+
+// FetchCountriesContext fetches postal code entries for multiple countries concurrently until ctx is done.
+// See the Client method of the same name for details.
+func FetchCountriesContext(ctx context.Context, ccs []string, etags map[string]string) (map[string]CountryResult, error) {
+	return defaultClient.FetchCountriesContext(ctx, ccs, etags)
+}
+
+// FetchCountries fetches postal code entries for the given country codes concurrently,
+// with at most c.Concurrency fetches in flight at a time.
+//
+// The ETags from previous requests are looked up in etags by country code as given in ccs.
+// A missing ETag is treated like an empty one, i.e. the data is always fetched.
+//
+// The results are keyed by country code as given in ccs. A failure to fetch one country does not abort
+// the others, unless the client's FailFast is set; instead, the error is reported in the Err field of that
+// country's result.
+func (c *Client) FetchCountries(ccs []string, etags map[string]string) map[string]CountryResult {
+	results, _ := c.FetchCountriesContext(context.Background(), ccs, etags)
+	return results
+}
+
+// FetchCountriesContext is like FetchCountries, but aborts all fetches once ctx is done, and additionally
+// returns an error summarizing the failures.
+//
+// By default, all countries are fetched regardless of failures, and the returned error joins the errors
+// of all failed countries. If the client's FailFast is set, the first failure cancels all fetches in flight
+// and prevents further ones from starting, and only that failure is returned.
+//
+// Either way, the results hold an entry for every country in ccs, so they tell which countries succeeded
+// and which failed. Countries whose fetch was canceled or never started report the error of the context.
+func (c *Client) FetchCountriesContext(ctx context.Context, ccs []string, etags map[string]string) (map[string]CountryResult, error) {
+	concurrency := c.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		results  = make(map[string]CountryResult, len(ccs))
+		queue    = make(chan string)
+		firstErr error
+	)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for cc := range queue {
+				var res FetchResult
+				err := ctx.Err()
+				if err == nil {
+					res, err = c.FetchCountryContext(ctx, cc, etags[cc])
+				}
+				mu.Lock()
+				results[cc] = CountryResult{FetchResult: res, Err: err}
+				if err != nil && c.FailFast && firstErr == nil {
+					firstErr = fmt.Errorf("fetch %s: %w", cc, err)
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	queued := 0
+enqueue:
+	for _, cc := range ccs {
+		select {
+		case queue <- cc:
+			queued++
+		case <-ctx.Done():
+			break enqueue
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	for _, cc := range ccs[queued:] {
+		if _, ok := results[cc]; !ok {
+			results[cc] = CountryResult{Err: ctx.Err()}
+		}
+	}
+	if firstErr != nil {
+		return results, firstErr
+	}
+	var errs []error
+	reported := make(map[string]bool)
+	for _, cc := range ccs {
+		if err := results[cc].Err; err != nil && !reported[cc] {
+			reported[cc] = true
+			errs = append(errs, fmt.Errorf("fetch %s: %w", cc, err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
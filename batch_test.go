@@ -0,0 +1,143 @@
+package geozip_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ngrash/geozip"
+)
+
+func TestClient_FetchCountries(t *testing.T) {
+	data, err := os.ReadFile("test_data/DE.zip")
+	if err != nil {
+		t.Fatal("read test data", err)
+	}
+	serve := serveBytes(data, `"new_etag"`)
+
+	var (
+		mu               sync.Mutex
+		inFlight, maxPar int
+	)
+	client := &geozip.Client{
+		Concurrency: 2,
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				mu.Lock()
+				inFlight++
+				maxPar = max(maxPar, inFlight)
+				mu.Unlock()
+				defer func() {
+					mu.Lock()
+					inFlight--
+					mu.Unlock()
+				}()
+
+				switch {
+				case strings.HasSuffix(r.URL.Path, "/DE.zip"):
+					return serve(r)
+				case strings.HasSuffix(r.URL.Path, "/AT.zip"):
+					if got, want := r.Header.Get("If-None-Match"), `"at_etag"`; got != want {
+						t.Errorf("client sent If-None-Match = %s, want %s", got, want)
+					}
+					return &http.Response{StatusCode: http.StatusNotModified}, nil
+				}
+				return &http.Response{StatusCode: http.StatusNotFound, Status: "404 Not Found"}, nil
+			}),
+		},
+	}
+
+	results := client.FetchCountries([]string{"DE", "AT", "ZZ"}, map[string]string{"AT": `"at_etag"`})
+	if got, want := len(results), 3; got != want {
+		t.Fatalf("len(results) = %v, want %v", got, want)
+	}
+
+	de := results["DE"]
+	if de.Err != nil {
+		t.Errorf("DE: err = %v, want nil", de.Err)
+	}
+	if got, want := len(de.Entries), 16477; got != want {
+		t.Errorf("DE: len(Entries) = %v, want %v", got, want)
+	}
+
+	at := results["AT"]
+	if at.Err != nil {
+		t.Errorf("AT: err = %v, want nil", at.Err)
+	}
+	if at.Modified {
+		t.Error("AT: Modified = true, want false")
+	}
+
+	if results["ZZ"].Err == nil {
+		t.Error("ZZ: err = nil, want error")
+	}
+
+	if maxPar > 2 {
+		t.Errorf("%d concurrent requests, want at most 2", maxPar)
+	}
+}
+
+func TestClient_FetchCountriesContext(t *testing.T) {
+	client := &geozip.Client{
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusNotFound, Status: "404 Not Found"}, nil
+			}),
+		},
+	}
+
+	results, err := client.FetchCountriesContext(context.Background(), []string{"AQ", "BV"}, nil)
+	if got, want := len(results), 2; got != want {
+		t.Fatalf("len(results) = %v, want %v", got, want)
+	}
+	if !errors.Is(err, geozip.ErrCountryNotFound) {
+		t.Errorf("err = %v, want %v", err, geozip.ErrCountryNotFound)
+	}
+	for _, cc := range []string{"AQ", "BV"} {
+		if results[cc].Err == nil {
+			t.Errorf("%s: err = nil, want error", cc)
+		}
+		if !strings.Contains(err.Error(), "fetch "+cc) {
+			t.Errorf("err = %v, want it to mention %s", err, cc)
+		}
+	}
+}
+
+func TestClient_FetchCountriesContext_FailFast(t *testing.T) {
+	data := zipArchive(t, map[string]string{"DE.txt": "DE\t54668\tFerschweiler\n"})
+	serve := serveBytes(data, `"etag"`)
+	var requested []string
+	client := &geozip.Client{
+		Concurrency: 1,
+		FailFast:    true,
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				requested = append(requested, r.URL.Path)
+				if strings.HasSuffix(r.URL.Path, "/AQ.zip") {
+					return &http.Response{StatusCode: http.StatusNotFound, Status: "404 Not Found"}, nil
+				}
+				return serve(r)
+			}),
+		},
+	}
+
+	results, err := client.FetchCountriesContext(context.Background(), []string{"AQ", "DE", "AT"}, nil)
+	if !errors.Is(err, geozip.ErrCountryNotFound) {
+		t.Errorf("err = %v, want %v", err, geozip.ErrCountryNotFound)
+	}
+	if got, want := len(results), 3; got != want {
+		t.Fatalf("len(results) = %v, want %v", got, want)
+	}
+	for _, cc := range []string{"DE", "AT"} {
+		if err := results[cc].Err; !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: err = %v, want %v", cc, err, context.Canceled)
+		}
+	}
+	if got, want := len(requested), 1; got != want {
+		t.Errorf("requested %v, want only AQ", requested)
+	}
+}
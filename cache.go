@@ -0,0 +1,159 @@
+package geozip
+
+import (
+	"sync"
+	"time"
+)
+
+// Cache keeps the most recently fetched entries and ETag per country in memory,
+// so repeated fetches of unchanged data neither re-download it nor require the caller to keep track of ETags.
+//
+// A Cache is safe for concurrent use. The zero value is ready to use.
+type Cache struct {
+	// Client is used for fetching. If nil, the package-level HTTPClient and defaults are used.
+	Client *Client
+	// TTL is how long a country is kept in the cache after it was last fetched.
+	// Once expired, the country is evicted and downloaded in full on the next fetch.
+	// If zero, countries are kept indefinitely.
+	TTL time.Duration
+	// Now, if not nil, returns the current time for the expiry of countries, e.g. to advance the clock
+	// deterministically in tests. If nil, time.Now is used.
+	Now func() time.Time
+
+	mu        sync.RWMutex
+	countries map[string]cached
+}
+
+// cached is a country held by a Cache.
+type cached struct {
+	entries []Entry
+	etag    string
+	fetched time.Time
+}
+
+// CachedFetch fetches postal code entries for a specific country code, supplying the ETag of the cached entries.
+// If the data has not been modified, the cached entries are returned with modified set to false.
+// Otherwise, the new entries replace the cached ones and are returned with modified set to true.
+func (c *Cache) CachedFetch(cc string) (entries []Entry, modified bool, err error) {
+	cc, err = normalizeCountryCode(cc)
+	if err != nil {
+		return nil, false, err
+	}
+
+	prev, ok := c.lookup(cc)
+	var etag string
+	if ok {
+		etag = prev.etag
+	}
+
+	entries, modified, newEtag, err := orDefault(c.Client).FetchCountry(cc, etag)
+	if err != nil {
+		return nil, false, err
+	}
+	if !modified {
+		entries = prev.entries
+	}
+
+	c.store(cc, cached{entries: entries, etag: newEtag, fetched: c.now()})
+
+	return entries, modified, nil
+}
+
+// FetchCountryMaxAge is like CachedFetch, but returns the cached entries without any request if they were
+// fetched or revalidated within maxAge. Unlike a 304 Not Modified response to a conditional request,
+// this avoids the round trip altogether. The cached entries are returned with modified set to false.
+func (c *Cache) FetchCountryMaxAge(cc string, maxAge time.Duration) (entries []Entry, modified bool, err error) {
+	cc, err = normalizeCountryCode(cc)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if prev, ok := c.lookup(cc); ok && c.now().Sub(prev.fetched) <= maxAge {
+		return prev.entries, false, nil
+	}
+	return c.CachedFetch(cc)
+}
+
+// now returns the current time according to c.Now.
+func (c *Cache) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
+
+func (c *Cache) store(cc string, country cached) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.countries == nil {
+		c.countries = make(map[string]cached)
+	}
+	c.countries[cc] = country
+}
+
+// lookup returns the cached country, evicting it if it has expired.
+func (c *Cache) lookup(cc string) (cached, bool) {
+	c.mu.RLock()
+	prev, ok := c.countries[cc]
+	c.mu.RUnlock()
+	if ok && c.TTL > 0 && c.now().Sub(prev.fetched) > c.TTL {
+		c.mu.Lock()
+		// A concurrent fetch may have stored fresh data since the read lock was released.
+		if cur, ok := c.countries[cc]; ok && cur.fetched.Equal(prev.fetched) {
+			delete(c.countries, cc)
+		}
+		c.mu.Unlock()
+		return cached{}, false
+	}
+	return prev, ok
+}
+
+// orDefault returns c, or the client backing the package-level functions if c is nil.
+func orDefault(c *Client) *Client {
+	if c == nil {
+		return defaultClient
+	}
+	return c
+}
+
+// CachedClient fetches postal code data like a Client, but keeps the most recently fetched entries per country,
+// so that it can return them when the server reports that the data has not been modified.
+// This spares callers from keeping their own copy of the previous entries.
+//
+// A CachedClient is safe for concurrent use. The zero value is ready to use.
+type CachedClient struct {
+	// Client is used for fetching. If nil, the package-level HTTPClient and defaults are used.
+	Client *Client
+
+	cache Cache
+}
+
+// FetchCountry fetches postal code entries for a specific country code like Client.FetchCountry.
+// If the data has not been modified since the request with the provided ETag and the client holds the entries
+// of that version, they are returned along with modified set to false. If etag is empty, the ETag of the held
+// entries, if any, is sent.
+func (c *CachedClient) FetchCountry(cc, etag string) (entries []Entry, modified bool, newEtag string, err error) {
+	cc, err = normalizeCountryCode(cc)
+	if err != nil {
+		return nil, false, "", err
+	}
+
+	prev, ok := c.cache.lookup(cc)
+	if etag == "" && ok {
+		etag = prev.etag
+	}
+
+	entries, modified, newEtag, err = orDefault(c.Client).FetchCountry(cc, etag)
+	if err != nil {
+		return nil, false, "", err
+	}
+	if !modified {
+		if ok && prev.etag == newEtag {
+			entries = prev.entries
+		}
+		return entries, false, newEtag, nil
+	}
+
+	c.cache.store(cc, cached{entries: entries, etag: newEtag, fetched: c.cache.now()})
+	return entries, true, newEtag, nil
+}
@@ -0,0 +1,266 @@
+package geozip_test
+
+import (
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ngrash/geozip"
+)
+
+// etagServer returns a transport that serves data with etag and responds with 304 Not Modified
+// if the request carries the same ETag. It counts the full downloads in *downloads.
+func etagServer(t *testing.T, data []byte, etag string, downloads *int) RoundTripperFunc {
+	t.Helper()
+	serve := serveBytes(data, etag)
+	return func(r *http.Request) (*http.Response, error) {
+		if r.Header.Get("If-None-Match") == etag {
+			return &http.Response{StatusCode: http.StatusNotModified}, nil
+		}
+		*downloads++
+		return serve(r)
+	}
+}
+
+func TestCache_CachedFetch(t *testing.T) {
+	data, err := os.ReadFile("test_data/DE.zip")
+	if err != nil {
+		t.Fatal("read test data", err)
+	}
+	var downloads int
+	cache := &geozip.Cache{
+		Client: &geozip.Client{HTTPClient: &http.Client{Transport: etagServer(t, data, `"etag"`, &downloads)}},
+	}
+
+	entries, modified, err := cache.CachedFetch("DE")
+	if err != nil {
+		t.Fatalf("first fetch: err = %v, want nil", err)
+	}
+	if !modified {
+		t.Error("first fetch: modified = false, want true")
+	}
+	if got, want := len(entries), 16477; got != want {
+		t.Errorf("first fetch: len(entries) = %v, want %v", got, want)
+	}
+
+	entries, modified, err = cache.CachedFetch("de")
+	if err != nil {
+		t.Fatalf("second fetch: err = %v, want nil", err)
+	}
+	if modified {
+		t.Error("second fetch: modified = true, want false")
+	}
+	if got, want := len(entries), 16477; got != want {
+		t.Errorf("second fetch: len(entries) = %v, want %v", got, want)
+	}
+
+	if got, want := downloads, 1; got != want {
+		t.Errorf("%d downloads, want %d", got, want)
+	}
+}
+
+func TestCache_FetchCountryMaxAge(t *testing.T) {
+	data := zipArchive(t, map[string]string{"DE.txt": "DE\t54668\tFerschweiler\n"})
+	serve := serveBytes(data, `"etag"`)
+	requests := 0
+	cache := &geozip.Cache{
+		Client: &geozip.Client{HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				requests++
+				return serve(r)
+			}),
+		}},
+	}
+
+	if _, modified, err := cache.FetchCountryMaxAge("DE", time.Hour); err != nil || !modified {
+		t.Fatalf("first fetch: modified, err = %v, %v, want true, nil", modified, err)
+	}
+	entries, modified, err := cache.FetchCountryMaxAge("DE", time.Hour)
+	if err != nil || modified || len(entries) != 1 {
+		t.Errorf("fresh fetch: len(entries), modified, err = %v, %v, %v, want 1, false, nil", len(entries), modified, err)
+	}
+	if got, want := requests, 1; got != want {
+		t.Errorf("%d requests after fresh fetch, want %d", got, want)
+	}
+
+	time.Sleep(time.Millisecond)
+	if _, _, err := cache.FetchCountryMaxAge("DE", time.Nanosecond); err != nil {
+		t.Fatalf("stale fetch: err = %v, want nil", err)
+	}
+	if got, want := requests, 2; got != want {
+		t.Errorf("%d requests after stale fetch, want %d", got, want)
+	}
+}
+
+func TestCache_TTL(t *testing.T) {
+	data, err := os.ReadFile("test_data/DE.zip")
+	if err != nil {
+		t.Fatal("read test data", err)
+	}
+	var downloads int
+	cache := &geozip.Cache{
+		Client: &geozip.Client{HTTPClient: &http.Client{Transport: etagServer(t, data, `"etag"`, &downloads)}},
+		TTL:    time.Nanosecond,
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := cache.CachedFetch("DE"); err != nil {
+			t.Fatalf("fetch %d: err = %v, want nil", i, err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if got, want := downloads, 2; got != want {
+		t.Errorf("%d downloads, want %d", got, want)
+	}
+}
+
+func TestCache_Now(t *testing.T) {
+	data := zipArchive(t, map[string]string{"DE.txt": "DE\t54668\tFerschweiler\n"})
+	var downloads int
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := &geozip.Cache{
+		Client: &geozip.Client{HTTPClient: &http.Client{Transport: etagServer(t, data, `"etag"`, &downloads)}},
+		TTL:    time.Hour,
+		Now:    func() time.Time { return now },
+	}
+
+	steps := []struct {
+		advance       time.Duration
+		wantDownloads int
+	}{
+		{0, 1},
+		// Within the TTL, the cached ETag is sent and the data is revalidated.
+		{59 * time.Minute, 1},
+		// The revalidation restarted the TTL.
+		{59 * time.Minute, 1},
+		{time.Hour + time.Second, 2},
+	}
+	for i, step := range steps {
+		now = now.Add(step.advance)
+		if _, _, err := cache.CachedFetch("DE"); err != nil {
+			t.Fatalf("step %d: err = %v, want nil", i, err)
+		}
+		if downloads != step.wantDownloads {
+			t.Errorf("step %d: %d downloads, want %d", i, downloads, step.wantDownloads)
+		}
+	}
+
+	now = now.Add(30 * time.Minute)
+	if _, modified, err := cache.FetchCountryMaxAge("DE", 30*time.Minute); err != nil || modified {
+		t.Errorf("FetchCountryMaxAge: modified, err = %v, %v, want false, nil", modified, err)
+	}
+}
+
+func TestCache_TTL_ConcurrentStore(t *testing.T) {
+	data := zipArchive(t, map[string]string{"DE.txt": "DE\t54668\tFerschweiler\n"})
+	var downloads int
+	serve := etagServer(t, data, `"etag"`, &downloads)
+	fail := false
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var interleave func()
+	cache := &geozip.Cache{
+		Client: &geozip.Client{HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				if fail {
+					return &http.Response{StatusCode: http.StatusForbidden, Status: "403 Forbidden"}, nil
+				}
+				return serve(r)
+			}),
+		}},
+		TTL: time.Hour,
+		Now: func() time.Time {
+			if fn := interleave; fn != nil {
+				interleave = nil
+				fn()
+			}
+			return now
+		},
+	}
+
+	if _, _, err := cache.CachedFetch("DE"); err != nil {
+		t.Fatalf("first fetch: err = %v, want nil", err)
+	}
+
+	// While a fetch finds the country expired, another fetch stores fresh data before the first evicts it.
+	now = now.Add(2 * time.Hour)
+	interleave = func() {
+		if _, _, err := cache.CachedFetch("DE"); err != nil {
+			t.Errorf("interleaved fetch: err = %v, want nil", err)
+		}
+		fail = true
+	}
+	if _, _, err := cache.CachedFetch("DE"); err == nil {
+		t.Fatal("failing fetch: err = nil, want error")
+	}
+
+	// The fresh data survived, so it is revalidated rather than downloaded again.
+	fail = false
+	if _, modified, err := cache.CachedFetch("DE"); err != nil || modified {
+		t.Errorf("last fetch: modified, err = %v, %v, want false, nil", modified, err)
+	}
+	if got, want := downloads, 2; got != want {
+		t.Errorf("%d downloads, want %d", got, want)
+	}
+}
+
+func TestCachedClient_FetchCountry(t *testing.T) {
+	data, err := os.ReadFile("test_data/DE.zip")
+	if err != nil {
+		t.Fatal("read test data", err)
+	}
+	var downloads int
+	client := &geozip.CachedClient{
+		Client: &geozip.Client{HTTPClient: &http.Client{Transport: etagServer(t, data, `"etag"`, &downloads)}},
+	}
+
+	entries, modified, newEtag, err := client.FetchCountry("DE", "")
+	if err != nil {
+		t.Fatalf("first fetch: err = %v, want nil", err)
+	}
+	if !modified {
+		t.Error("first fetch: modified = false, want true")
+	}
+	if got, want := len(entries), 16477; got != want {
+		t.Errorf("first fetch: len(entries) = %v, want %v", got, want)
+	}
+
+	entries, modified, _, err = client.FetchCountry("DE", newEtag)
+	if err != nil {
+		t.Fatalf("second fetch: err = %v, want nil", err)
+	}
+	if modified {
+		t.Error("second fetch: modified = true, want false")
+	}
+	if got, want := len(entries), 16477; got != want {
+		t.Errorf("second fetch: len(entries) = %v, want %v", got, want)
+	}
+
+	if got, want := downloads, 1; got != want {
+		t.Errorf("%d downloads, want %d", got, want)
+	}
+}
+
+func TestCachedClient_FetchCountry_UnknownVersion(t *testing.T) {
+	client := &geozip.CachedClient{
+		Client: &geozip.Client{
+			HTTPClient: &http.Client{
+				Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+					return &http.Response{StatusCode: http.StatusNotModified}, nil
+				}),
+			},
+		},
+	}
+
+	entries, modified, _, err := client.FetchCountry("DE", `"etag"`)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if modified {
+		t.Error("modified = true, want false")
+	}
+	if entries != nil {
+		t.Errorf("entries = %v, want nil", entries)
+	}
+}
@@ -0,0 +1,25 @@
+package geozip
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// VerifyChecksum reads tab-separated postal code data, such as the text returned by FetchCountryRaw, from r
+// and checks it against checksum, the hex-encoded SHA-256 checksum reported as FetchResult.Checksum when the data
+// was fetched. This detects data that was corrupted after it was fetched, e.g. in a cache on disk.
+// The data is hashed as it is read, without buffering it.
+// If the data does not match, VerifyChecksum returns an error wrapping ErrChecksumMismatch.
+func VerifyChecksum(r io.Reader, checksum string) error {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return fmt.Errorf("read data: %w", err)
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(got, checksum) {
+		return fmt.Errorf("%w: got %s, want %s", ErrChecksumMismatch, got, checksum)
+	}
+	return nil
+}
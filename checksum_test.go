@@ -0,0 +1,60 @@
+package geozip_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ngrash/geozip"
+)
+
+func TestClient_Checksum(t *testing.T) {
+	const text = "DE\t54668\tFerschweiler\nDE\t54636\tBitburg\n"
+	data := zipArchive(t, map[string]string{"DE.txt": text})
+	sum := sha256.Sum256([]byte(text))
+	want := hex.EncodeToString(sum[:])
+
+	client := &geozip.Client{HTTPClient: &http.Client{Transport: serveBytes(data, `"etag"`)}}
+	res, err := client.FetchCountryResult("DE", "")
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if res.Checksum != "" {
+		t.Errorf("without Checksum: Checksum = %q, want empty", res.Checksum)
+	}
+
+	client.Checksum = true
+	res, err = client.FetchCountryResult("DE", "")
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if res.Checksum != want {
+		t.Errorf("Checksum = %q, want %q", res.Checksum, want)
+	}
+
+	raw, _, _, err := client.FetchCountryRaw("DE", "")
+	if err != nil {
+		t.Fatalf("FetchCountryRaw: err = %v, want nil", err)
+	}
+	if err := geozip.VerifyChecksum(bytes.NewReader(raw), res.Checksum); err != nil {
+		t.Errorf("VerifyChecksum(raw) = %v, want nil", err)
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	const text = "DE\t54668\tFerschweiler\n"
+	sum := sha256.Sum256([]byte(text))
+	checksum := hex.EncodeToString(sum[:])
+
+	if err := geozip.VerifyChecksum(strings.NewReader(text), strings.ToUpper(checksum)); err != nil {
+		t.Errorf("upper case checksum: err = %v, want nil", err)
+	}
+	err := geozip.VerifyChecksum(strings.NewReader("DE\t54668\tGerschweiler\n"), checksum)
+	if !errors.Is(err, geozip.ErrChecksumMismatch) {
+		t.Errorf("corrupt data: err = %v, want %v", err, geozip.ErrChecksumMismatch)
+	}
+}
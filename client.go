@@ -0,0 +1,826 @@
+package geozip
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// HTTPClient is a global http.Client instance used for making HTTP requests.
+// This can be replaced or configured as needed to change the default HTTP behavior.
+// It is initialized by NewHTTPClient. If set to nil, http.DefaultClient is used.
+//
+// It is used by the package-level functions and by any Client whose HTTPClient field is nil.
+var HTTPClient = NewHTTPClient()
+
+// NewHTTPClient returns an http.Client suited for fetching postal code data. Its transport is a clone of
+// http.DefaultTransport, so it honors proxy settings from the environment and uses HTTP/2 where available,
+// but keeps enough idle connections per host to reuse them when fetching many countries concurrently.
+// If http.DefaultTransport has been replaced by another kind of http.RoundTripper, a new transport with the
+// same defaults as the standard library's is used instead.
+func NewHTTPClient() *http.Client {
+	var transport *http.Transport
+	if t, ok := http.DefaultTransport.(*http.Transport); ok {
+		transport = t.Clone()
+	} else {
+		transport = &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		}
+	}
+	transport.MaxIdleConnsPerHost = 2 * DefaultConcurrency
+	return &http.Client{Transport: transport}
+}
+
+// DefaultBaseURL is the base URL of the GeoNames postal code downloads.
+const DefaultBaseURL = "https://download.geonames.org/export/zip"
+
+// defaultClient backs the package-level functions.
+var defaultClient = &Client{}
+
+// Client fetches postal code data from the GeoNames database.
+//
+// Unlike the package-level functions, a Client does not depend on global state, so independent
+// clients with different transports, timeouts or proxies can be used side by side.
+// The zero value is ready to use and falls back to the package-level HTTPClient.
+type Client struct {
+	// HTTPClient is used for making HTTP requests. If nil, the package-level HTTPClient is used.
+	HTTPClient *http.Client
+	// BaseURL is the URL of the directory holding the zip archives, e.g. of an internal mirror.
+	// If empty, DefaultBaseURL is used. Trailing slashes are ignored.
+	//
+	// A file URL, e.g. "file:///srv/geonames" or "file:///C:/geonames" on Windows, reads the archives from a local
+	// directory instead, such as in air-gapped environments. The URL must hold an absolute path and no host other
+	// than "localhost"; relative forms like "file://data" or "file:data" are rejected, as the host is not part of
+	// the path. The files are served without an ETag, so they are always reported as modified, unless
+	// FetchCountryIfModified is used without an ETag and the file has not changed since.
+	BaseURL string
+	// FallbackURLs are tried in order if an archive cannot be downloaded from BaseURL due to a connection error
+	// or a server error, after any retries. For example, DefaultBaseURL may serve as a fallback for a mirror.
+	// Trailing slashes are ignored. By default, there are no fallbacks.
+	FallbackURLs []string
+	// UserAgent, if not empty, is sent as the User-Agent header of every request,
+	// e.g. to identify an application to GeoNames.
+	UserAgent string
+	// RetryPolicy configures retries of transiently failed requests. The zero value disables retries.
+	RetryPolicy RetryPolicy
+	// RequestModifier, if not nil, is called with every request before it is sent, e.g. to set an Authorization
+	// header for a mirror. It is called after the conditional request headers, such as If-None-Match, are set,
+	// so it may override them as well.
+	RequestModifier func(*http.Request)
+	// Progress, if not nil, is called repeatedly while a response body is read, with the number of bytes read
+	// so far and the total length from the Content-Length header, or -1 if the length is unknown.
+	Progress func(bytesRead, totalBytes int64)
+	// Limiter, if not nil, throttles requests, e.g. to respect the limits of GeoNames when fetching many countries.
+	// Each request, including retries, waits for the limiter first. By default, requests are not throttled.
+	Limiter Limiter
+	// MemberPattern, if not empty, selects the member of an archive to parse by a glob pattern as understood by
+	// path.Match, e.g. "DE*.txt", instead of the exact name "<cc>.txt". This helps with mirrors that add suffixes
+	// to the member names. The readme is never matched. Exactly one member must match, otherwise fetching fails.
+	// Explicitly named members, as for FetchCountryMember, take precedence.
+	MemberPattern string
+	// ConcatMembers makes the functions that parse entries, such as FetchCountry, parse all .txt members of an
+	// archive other than the readme, or all members matching MemberPattern if set, and concatenate their entries
+	// in the order of the member names. This supports archives that split the data of a country into several
+	// members, e.g. by region. By default, a single member is parsed. Explicitly named members take precedence.
+	ConcatMembers bool
+	// Checksum makes the functions that parse entries, such as FetchCountryResult, compute the SHA-256 checksum
+	// of the decompressed tab-separated text while parsing it, reported as FetchResult.Checksum.
+	// By default, no checksum is computed.
+	Checksum bool
+	// OnRequest, if not nil, is called after each request, including retries, with information suitable for
+	// logging or metrics. For example, to log requests with log/slog:
+	//
+	//	client.OnRequest = func(info geozip.RequestInfo) {
+	//	    slog.Info("geozip request", "url", info.URL, "status", info.StatusCode, "bytes", info.Bytes,
+	//	        "modified", info.Modified, "duration", info.Duration, "err", info.Err)
+	//	}
+	//
+	// By default, requests are not logged.
+	OnRequest func(RequestInfo)
+	// MaxBytes, if positive, limits the size of a response body, after decompression of any gzip
+	// Content-Encoding. Larger responses fail with ErrResponseTooLarge instead of being read into memory, which
+	// protects against misconfigured or untrusted mirrors. For reference, allCountries.zip is about 20 MiB.
+	// By default, the size is not limited.
+	MaxBytes int64
+	// Concurrency limits the number of countries fetched concurrently by FetchCountries.
+	// If zero or negative, DefaultConcurrency is used.
+	Concurrency int
+	// FailFast makes FetchCountries and FetchCountriesContext stop at the first country that fails to fetch,
+	// canceling the fetches in flight. By default, all countries are fetched regardless of failures.
+	FailFast bool
+}
+
+// FetchResult holds the outcome of a fetch along with metadata taken from the response headers.
+type FetchResult struct {
+	// Entries holds the fetched postal code entries. It is nil if the data has not been modified,
+	// and a non-nil empty slice if the fetched data has no entries.
+	Entries []Entry
+	// Modified reports whether the data has changed since the request with the provided ETag.
+	Modified bool
+	// ETag is the ETag of the fetched data. Save it for future requests.
+	// It is normalized to the quoted form, e.g. "abc" or W/"abc", even if the server sent it unquoted.
+	ETag string
+	// LastModified is the time the data was last modified according to the Last-Modified header.
+	// It is the zero time if the header is absent or invalid.
+	LastModified time.Time
+	// ContentLength is the length of the downloaded archive according to the Content-Length header.
+	// It is -1 if the length is unknown.
+	ContentLength int64
+	// URL is the URL the archive was downloaded from. It tells which mirror served the data
+	// if the client has FallbackURLs.
+	URL string
+	// Member is the name of the archive member the entries were parsed from. If the client has ConcatMembers set,
+	// it lists the names of all parsed members, separated by ", ". It is empty if the data has not been modified.
+	Member string
+	// CompressedBytes is the size of the downloaded archive, after decoding any Content-Encoding.
+	// It is zero if the data has not been modified.
+	CompressedBytes int64
+	// DecompressedBytes is the size of the tab-separated text parsed from the archive member.
+	// It is zero if the data has not been modified. Comparing it to CompressedBytes gives the compression ratio.
+	DecompressedBytes int64
+	// Checksum is the hex-encoded SHA-256 checksum of the decompressed tab-separated text, of all parsed members
+	// in order, if the client has Checksum set. Store it along with the data to detect corruption later with
+	// VerifyChecksum. It is empty if the data has not been modified or no checksum was computed.
+	Checksum string
+}
+
+// FetchCountry fetches postal code entries for a specific country code using the client's configuration.
+// See the package-level FetchCountry for a description of the arguments and results.
+func (c *Client) FetchCountry(cc, etag string) (entries []Entry, modified bool, newEtag string, err error) {
+	res, err := c.FetchCountryResult(cc, etag)
+	return res.Entries, res.Modified, res.ETag, err
+}
+
+// FetchCountryResult is like FetchCountry but returns the result along with metadata taken from the response headers.
+func (c *Client) FetchCountryResult(cc, etag string) (FetchResult, error) {
+	return c.FetchCountryContext(context.Background(), cc, etag)
+}
+
+// FetchCountryContext is like FetchCountryResult but aborts the request, including any retries,
+// once ctx is done.
+func (c *Client) FetchCountryContext(ctx context.Context, cc, etag string) (FetchResult, error) {
+	cc, err := normalizeCountryCode(cc)
+	if err != nil {
+		return FetchResult{}, err
+	}
+
+	return c.fetch(ctx, cc, "", validators{etag: etag}, parseConfig{})
+}
+
+// FetchCountryTimeout is like FetchCountryResult but aborts the request, including any retries,
+// once the timeout has elapsed. A zero timeout means no timeout.
+func (c *Client) FetchCountryTimeout(cc, etag string, timeout time.Duration) (FetchResult, error) {
+	ctx := context.Background()
+	if timeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return c.FetchCountryContext(ctx, cc, etag)
+}
+
+// FetchCountryIfModified is like FetchCountryResult, but additionally sends the If-Modified-Since header
+// if lastModified is not the zero time. Pass the LastModified of a previous result, so that the data is only
+// fetched if it has changed. This helps with caching proxies that honor Last-Modified better than ETags.
+//
+// If the data has not been modified, the result has Modified set to false and carries the Last-Modified time
+// of the response, falling back to the given lastModified if the response has none.
+func (c *Client) FetchCountryIfModified(cc, etag string, lastModified time.Time) (FetchResult, error) {
+	cc, err := normalizeCountryCode(cc)
+	if err != nil {
+		return FetchResult{}, err
+	}
+
+	return c.fetch(context.Background(), cc, "", validators{etag: etag, lastModified: lastModified}, parseConfig{})
+}
+
+// FetchCountryMember fetches postal code entries for a specific country code like FetchCountry,
+// but parses the named member of the archive instead of "<cc>.txt". If member is empty, the default is used.
+// See the package-level FetchCountryMember for details.
+func (c *Client) FetchCountryMember(cc, etag, member string) (entries []Entry, modified bool, newEtag string, err error) {
+	cc, err = normalizeCountryCode(cc)
+	if err != nil {
+		return
+	}
+	res, err := c.fetch(context.Background(), cc, member, validators{etag: etag}, parseConfig{})
+	return res.Entries, res.Modified, res.ETag, err
+}
+
+// FetchCountryFiltered fetches postal code entries for a specific country code like FetchCountry,
+// but only returns the entries for which keep returns true.
+// The predicate is applied as the rows are parsed, so discarded entries are never collected.
+func (c *Client) FetchCountryFiltered(cc, etag string, keep func(Entry) bool) (entries []Entry, modified bool, newEtag string, err error) {
+	cc, err = normalizeCountryCode(cc)
+	if err != nil {
+		return
+	}
+
+	res, err := c.fetch(context.Background(), cc, "", validators{etag: etag}, parseConfig{keep: keep})
+	return res.Entries, res.Modified, res.ETag, err
+}
+
+// FetchCountryInto fetches postal code entries for a specific country code like FetchCountry,
+// but appends them to dst, reusing its capacity. Like append, it returns the possibly grown slice.
+// If the data has not been modified, or an error occurs, dst is returned unchanged.
+//
+// In long-running services that refresh data periodically, passing the previous slice truncated to zero length
+// avoids allocating a new slice on every refresh.
+func (c *Client) FetchCountryInto(cc, etag string, dst []Entry) (entries []Entry, modified bool, newEtag string, err error) {
+	cc, err = normalizeCountryCode(cc)
+	if err != nil {
+		return dst, false, "", err
+	}
+
+	res, err := c.fetch(context.Background(), cc, "", validators{etag: etag}, parseConfig{dst: dst})
+	if res.Entries == nil {
+		res.Entries = dst
+	}
+	return res.Entries, res.Modified, res.ETag, err
+}
+
+// FetchAll fetches the combined postal code entries of all countries using the client's configuration.
+// See the package-level FetchAll for details.
+func (c *Client) FetchAll(etag string) (entries []Entry, modified bool, newEtag string, err error) {
+	res, err := c.fetch(context.Background(), allCountries, "", validators{etag: etag}, parseConfig{})
+	return res.Entries, res.Modified, res.ETag, err
+}
+
+// FetchAllIndexed fetches the combined postal code entries of all countries like FetchAll, but builds an Index
+// from them. See the package-level FetchAllIndexed for details.
+func (c *Client) FetchAllIndexed(etag string) (idx *Index, modified bool, newEtag string, err error) {
+	idx = emptyIndex(false)
+	res, err := c.fetch(context.Background(), allCountries, "", validators{etag: etag}, parseConfig{
+		keep: func(e Entry) bool {
+			idx.entries = append(idx.entries, e)
+			idx.add(len(idx.entries) - 1)
+			return false
+		},
+	})
+	if err != nil || !res.Modified {
+		return nil, res.Modified, res.ETag, err
+	}
+	idx.sortNames()
+	return idx, true, res.ETag, nil
+}
+
+// FetchCountryRaw fetches the postal code data for a specific country code like FetchCountry,
+// but returns the decompressed tab-separated text verbatim instead of parsing it.
+func (c *Client) FetchCountryRaw(cc, etag string) (data []byte, modified bool, newEtag string, err error) {
+	cc, err = normalizeCountryCode(cc)
+	if err != nil {
+		return
+	}
+	return c.fetchMember(cc, etag, "", true)
+}
+
+// FetchMember fetches the content of the named member of the archive for a specific country code.
+// See the package-level FetchMember for details.
+func (c *Client) FetchMember(cc, etag, member string) (data []byte, modified bool, newEtag string, err error) {
+	cc, err = normalizeCountryCode(cc)
+	if err != nil {
+		return
+	}
+	return c.fetchMember(cc, etag, member, false)
+}
+
+// fetchMember downloads the archive for cc and reads the named member, or the default member if filename is empty,
+// falling back to another .txt member like unzipFile if fallback is set.
+func (c *Client) fetchMember(cc, etag, filename string, fallback bool) (data []byte, modified bool, newEtag string, err error) {
+	resp, err := c.downloadArchive(context.Background(), cc, validators{etag: etag})
+	if err != nil || !resp.modified {
+		return nil, resp.modified, resp.etag, err
+	}
+	if filename, err = c.member(resp.body, cc, filename); err != nil {
+		return nil, false, "", err
+	}
+
+	rc, filename, err := openMember(bytes.NewReader(resp.body), int64(len(resp.body)), filename, fallback)
+	if err != nil {
+		return nil, false, "", err
+	}
+	data, err = io.ReadAll(rc)
+	if err = errors.Join(err, rc.Close()); err != nil {
+		return nil, false, "", fmt.Errorf("read zipped %s: %w", filename, err)
+	}
+	return data, true, resp.etag, nil
+}
+
+// CountCountry counts the postal code entries for a specific country code like the package-level CountCountry.
+func (c *Client) CountCountry(cc, etag string) (count int, modified bool, newEtag string, err error) {
+	cc, err = normalizeCountryCode(cc)
+	if err != nil {
+		return
+	}
+
+	resp, err := c.downloadArchive(context.Background(), cc, validators{etag: etag})
+	if err != nil || !resp.modified {
+		return 0, resp.modified, resp.etag, err
+	}
+
+	filename, err := c.member(resp.body, cc, "")
+	if err != nil {
+		return 0, false, "", err
+	}
+	rc, err := unzipFile(bytes.NewReader(resp.body), int64(len(resp.body)), filename)
+	if err != nil {
+		return 0, false, "", err
+	}
+	count, err = countLines(rc)
+	if err = errors.Join(err, rc.Close()); err != nil {
+		return 0, false, "", fmt.Errorf("read zipped %s: %w", filename, err)
+	}
+	return count, true, resp.etag, nil
+}
+
+// countLines counts the non-empty lines read from r.
+func countLines(r io.Reader) (int, error) {
+	buf := make([]byte, 32*1024)
+	count := 0
+	inLine := false
+	for {
+		n, err := r.Read(buf)
+		for _, b := range buf[:n] {
+			switch b {
+			case '\n':
+				if inLine {
+					count++
+				}
+				inLine = false
+			case '\r':
+			default:
+				inLine = true
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	if inLine {
+		count++
+	}
+	return count, nil
+}
+
+// fetch downloads the zip archive with the given name and parses the named member as configured by cfg.
+// If filename is empty, the default member is parsed, see Client.member, falling back to another member like
+// unzipFile. A named member does not fall back, so a misspelled filename fails with ErrMemberNotFound.
+func (c *Client) fetch(ctx context.Context, name, filename string, v validators, cfg parseConfig) (FetchResult, error) {
+	resp, err := c.downloadArchive(ctx, name, v)
+	if err != nil {
+		return FetchResult{}, err
+	}
+
+	res := FetchResult{
+		Modified:      resp.modified,
+		ETag:          resp.etag,
+		LastModified:  resp.lastModified,
+		ContentLength: resp.contentLength,
+		URL:           resp.url,
+	}
+	if !res.Modified {
+		return res, nil
+	}
+
+	fallback := filename == ""
+	var members []string
+	if filename == "" && c.ConcatMembers {
+		members, err = matchMembers(bytes.NewReader(resp.body), int64(len(resp.body)), c.MemberPattern)
+	} else {
+		filename, err = c.member(resp.body, name, filename)
+		members = []string{filename}
+	}
+	if err != nil {
+		return FetchResult{}, err
+	}
+
+	res.CompressedBytes = int64(len(resp.body))
+	var sum hash.Hash
+	if c.Checksum {
+		sum = sha256.New()
+		cfg.hash = sum
+	}
+	names := make([]string, 0, len(members))
+	for _, filename := range members {
+		var member memberInfo
+		res.Entries, member, err = parseZip(bytes.NewReader(resp.body), int64(len(resp.body)), filename, fallback, cfg)
+		if err != nil {
+			return res, err
+		}
+		// Append the entries of the next member to those parsed so far.
+		cfg.dst = res.Entries
+		names = append(names, member.name)
+		res.DecompressedBytes += member.size
+	}
+	res.Member = strings.Join(names, ", ")
+	if sum != nil {
+		res.Checksum = hex.EncodeToString(sum.Sum(nil))
+	}
+	return res, nil
+}
+
+// member returns the name of the member to read from archive, the downloaded zip archive with the given name.
+// This is filename if not empty. Otherwise, it is the single member matching the client's MemberPattern, if set,
+// or "<name>.txt" by default.
+func (c *Client) member(archive []byte, name, filename string) (string, error) {
+	if filename != "" {
+		return filename, nil
+	}
+	if c.MemberPattern == "" {
+		return zippedFile(name), nil
+	}
+	return matchMember(bytes.NewReader(archive), int64(len(archive)), c.MemberPattern)
+}
+
+// downloadArchive downloads the zip archive with the given name, e.g. "DE" for DE.zip,
+// reporting a missing archive as ErrCountryNotFound.
+//
+// If the download from the base URL fails with a connection error or a server error, the client's FallbackURLs
+// are tried in order. If all of them fail, the errors are joined.
+func (c *Client) downloadArchive(ctx context.Context, name string, v validators) (downloadResult, error) {
+	var errs []error
+	for i, base := range c.baseURLs() {
+		url := archiveURL(base, name)
+		resp, err := c.download(ctx, url, v)
+		resp.url = url
+		var serr *statusError
+		if errors.As(err, &serr) && serr.code == http.StatusNotFound {
+			return downloadResult{}, fmt.Errorf("%w: %w", ErrCountryNotFound, err)
+		}
+		if err == nil || !canFallBack(ctx, err) {
+			return resp, err
+		}
+		if i > 0 {
+			err = fmt.Errorf("fallback %s: %w", base, err)
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 1 {
+		return downloadResult{}, errs[0]
+	}
+	return downloadResult{}, errors.Join(errs...)
+}
+
+// canFallBack reports whether a download that failed with err might succeed from another server,
+// i.e. whether it failed due to a connection error or a server error rather than the request itself.
+func canFallBack(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	// Any server error qualifies, including those that are not retried, such as 501 Not Implemented.
+	var serr *statusError
+	if errors.As(err, &serr) {
+		return serr.code >= 500
+	}
+	var rerr *retriableError
+	return errors.As(err, &rerr)
+}
+
+// downloadURL returns the URL of the zip archive with the given name, e.g. "DE" for DE.zip.
+func (c *Client) downloadURL(name string) string {
+	return archiveURL(c.baseURL(), name)
+}
+
+func archiveURL(base, name string) string {
+	return fmt.Sprintf("%s/%s.zip", base, name)
+}
+
+// baseURLs returns the client's base URL followed by its fallback URLs, without trailing slashes.
+func (c *Client) baseURLs() []string {
+	urls := []string{c.baseURL()}
+	for _, u := range c.FallbackURLs {
+		urls = append(urls, strings.TrimRight(u, "/"))
+	}
+	return urls
+}
+
+// baseURL returns the client's base URL without trailing slashes.
+func (c *Client) baseURL() string {
+	base := c.BaseURL
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	return strings.TrimRight(base, "/")
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	if HTTPClient != nil {
+		return HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// validators identify a previously fetched version of the data for a conditional request.
+type validators struct {
+	etag         string
+	lastModified time.Time
+}
+
+// downloadResult is the outcome of a successful request made by Client.download.
+type downloadResult struct {
+	body          []byte
+	modified      bool
+	etag          string
+	lastModified  time.Time
+	contentLength int64
+	url           string
+}
+
+// download requests url, retrying transient failures according to the client's RetryPolicy.
+func (c *Client) download(ctx context.Context, url string, v validators) (res downloadResult, err error) {
+	err = c.RetryPolicy.do(ctx, func() error {
+		res, err = c.downloadOnce(ctx, url, v)
+		return err
+	})
+	if err != nil {
+		return downloadResult{}, err
+	}
+	return res, nil
+}
+
+// RequestInfo describes a request made by a Client, as reported to its OnRequest hook.
+type RequestInfo struct {
+	// Method is the HTTP method, e.g. "GET".
+	Method string
+	// URL is the requested URL.
+	URL string
+	// StatusCode is the HTTP status code of the response, or 0 if no response was received.
+	StatusCode int
+	// Bytes is the size of the response body after decompression of any Content-Encoding,
+	// or 0 if the body was not read, e.g. for 304 Not Modified.
+	Bytes int64
+	// Modified reports whether new data was received.
+	Modified bool
+	// Duration is the time from sending the request, including any wait for the Limiter, until the body was read.
+	Duration time.Duration
+	// Err is the error the request failed with, if any.
+	Err error
+}
+
+// logRequest reports info to the client's OnRequest hook, if any.
+func (c *Client) logRequest(info RequestInfo) {
+	if c.OnRequest != nil {
+		c.OnRequest(info)
+	}
+}
+
+// do sends req after waiting for the client's Limiter and applying its UserAgent and RequestModifier.
+// Transport errors are marked as retriable.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if c.Limiter != nil {
+		if err := c.Limiter.Wait(req.Context()); err != nil {
+			return nil, fmt.Errorf("wait for rate limiter: %w", err)
+		}
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	if c.RequestModifier != nil {
+		c.RequestModifier(req)
+	}
+	var resp *http.Response
+	var err error
+	if req.URL.Scheme == "file" {
+		if err := checkFileURL(req.URL); err != nil {
+			return nil, err
+		}
+		resp, err = fileTransport.RoundTrip(req)
+	} else {
+		resp, err = c.httpClient().Do(req)
+	}
+	if err != nil {
+		return nil, &retriableError{err: err}
+	}
+	return resp, nil
+}
+
+// fileTransport serves requests for file URLs from the local file system, bypassing the HTTP client.
+var fileTransport = http.NewFileTransport(localFiles{})
+
+// checkFileURL reports an error if u is not a file URL with an absolute path on the local host.
+func checkFileURL(u *url.URL) error {
+	if u.Opaque != "" || (u.Host != "" && u.Host != "localhost") {
+		return fmt.Errorf("file URL %s has a host or relative path, want an absolute path like file:///srv/geonames", u)
+	}
+	if !filepath.IsAbs(localPath(u.Path)) {
+		return fmt.Errorf("file URL %s has a relative path, want an absolute path like file:///srv/geonames", u)
+	}
+	return nil
+}
+
+// localFiles opens the paths of file URLs in the local file system.
+type localFiles struct{}
+
+func (localFiles) Open(name string) (http.File, error) {
+	return os.Open(localPath(name))
+}
+
+// localPath converts the slash-separated path of a file URL to a local path.
+// On Windows, the path of a URL like file:///C:/geonames starts with a slash before the drive letter,
+// which is removed.
+func localPath(p string) string {
+	if runtime.GOOS == "windows" && len(p) >= 3 && p[0] == '/' && p[2] == ':' {
+		p = p[1:]
+	}
+	return filepath.FromSlash(p)
+}
+
+func (c *Client) downloadOnce(ctx context.Context, url string, v validators) (res downloadResult, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return downloadResult{}, err
+	}
+	etag := normalizeETag(v.etag)
+	req.Header.Add("If-None-Match", etag)
+	if !v.lastModified.IsZero() {
+		req.Header.Set("If-Modified-Since", v.lastModified.UTC().Format(http.TimeFormat))
+	}
+	start := time.Now()
+	status := 0
+	defer func() {
+		c.logRequest(RequestInfo{
+			Method:     req.Method,
+			URL:        url,
+			StatusCode: status,
+			Bytes:      int64(len(res.body)),
+			Modified:   res.modified,
+			Duration:   time.Since(start),
+			Err:        err,
+		})
+	}()
+	resp, err := c.do(req)
+	if err != nil {
+		return downloadResult{}, err
+	}
+	status = resp.StatusCode
+	defer func(Body io.ReadCloser) {
+		// Drain the body, whatever the status code, so that the connection can be reused.
+		_, drainErr := io.Copy(io.Discard, Body)
+		err = errors.Join(err, drainErr, Body.Close())
+	}(resp.Body)
+
+	// A missing or invalid Last-Modified header leaves the zero time.
+	lastModified, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+
+	if resp.StatusCode == http.StatusNotModified {
+		// No new codes and no error.
+		if lastModified.IsZero() {
+			lastModified = v.lastModified
+		}
+		return downloadResult{
+			etag:          etag,
+			lastModified:  lastModified,
+			contentLength: resp.ContentLength,
+		}, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		err := &statusError{code: resp.StatusCode, status: resp.Status}
+		if retriableStatus(resp.StatusCode) {
+			return downloadResult{}, &retriableError{err: err, retryAfter: retryAfter(resp)}
+		}
+		return downloadResult{}, err
+	}
+
+	body, err := c.readBody(resp)
+	if errors.Is(err, ErrResponseTooLarge) {
+		return downloadResult{}, err
+	}
+	if err != nil {
+		return downloadResult{}, &retriableError{err: fmt.Errorf("read response body: %w", err)}
+	}
+
+	return downloadResult{
+		body:          body,
+		modified:      true,
+		etag:          normalizeETag(resp.Header.Get("Etag")),
+		lastModified:  lastModified,
+		contentLength: resp.ContentLength,
+	}, nil
+}
+
+// normalizeETag returns etag in its quoted form, e.g. "abc" for abc and W/"abc" for W/abc, as some servers
+// send ETags with and some without quotes. Normalizing both the stored and the sent ETag keeps the round-trip stable,
+// so a server that quotes inconsistently still recognizes the data as unmodified. An empty etag is returned as is.
+func normalizeETag(etag string) string {
+	etag = strings.TrimSpace(etag)
+	if etag == "" {
+		return ""
+	}
+	etag, weak := strings.CutPrefix(etag, "W/")
+	if len(etag) < 2 || etag[0] != '"' || etag[len(etag)-1] != '"' {
+		etag = `"` + strings.Trim(etag, `"`) + `"`
+	}
+	if weak {
+		return "W/" + etag
+	}
+	return etag
+}
+
+// readBody reads the body of resp. A body with gzip Content-Encoding, as sent by some mirrors and proxies,
+// is decompressed transparently. This does not conflict with the transparent decompression of http.Transport,
+// which removes the Content-Encoding header when it decompresses the body itself.
+//
+// If the client's MaxBytes is positive, reading stops with ErrResponseTooLarge once the body, after decompression,
+// exceeds it.
+func (c *Client) readBody(resp *http.Response) ([]byte, error) {
+	if c.MaxBytes > 0 && resp.ContentLength > c.MaxBytes {
+		return nil, fmt.Errorf("%w: Content-Length %d exceeds %d bytes", ErrResponseTooLarge, resp.ContentLength, c.MaxBytes)
+	}
+	counter := &countingReader{r: resp.Body, total: resp.ContentLength, fn: c.Progress}
+	body, err := decodeBody(counter, resp, c.MaxBytes)
+	if c.MaxBytes > 0 && int64(len(body)) > c.MaxBytes {
+		return nil, fmt.Errorf("%w: body exceeds %d bytes", ErrResponseTooLarge, c.MaxBytes)
+	}
+	if (err == nil || errors.Is(err, io.ErrUnexpectedEOF)) && resp.ContentLength >= 0 && counter.read < resp.ContentLength {
+		return nil, fmt.Errorf("%w: read %d of %d bytes", ErrTruncatedDownload, counter.read, resp.ContentLength)
+	}
+	return body, err
+}
+
+// decodeBody reads r, the body of resp, decompressing it according to its Content-Encoding.
+// If limit is positive, it stops after reading one byte more than limit of the decompressed body.
+func decodeBody(r io.Reader, resp *http.Response, limit int64) (_ []byte, err error) {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return readAll(limitReader(r, limit), resp.ContentLength)
+	}
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("create gzip reader: %w", err)
+	}
+	defer func(gz io.ReadCloser) {
+		err = errors.Join(err, gz.Close())
+	}(gz)
+	return io.ReadAll(limitReader(gz, limit))
+}
+
+// limitReader returns a reader that stops after one byte more than limit, so that exceeding the limit can be
+// detected. If limit is not positive, r is returned unchanged.
+func limitReader(r io.Reader, limit int64) io.Reader {
+	if limit <= 0 {
+		return r
+	}
+	return io.LimitReader(r, limit+1)
+}
+
+// maxPrealloc caps the buffer preallocated by readAll, so that a bogus Content-Length cannot exhaust memory.
+const maxPrealloc = 64 << 20
+
+// readAll reads r until EOF like io.ReadAll. If the size is known, the buffer is allocated upfront,
+// which avoids repeatedly growing and copying the buffer while reading large archives.
+func readAll(r io.Reader, size int64) ([]byte, error) {
+	if size <= 0 || size > maxPrealloc {
+		return io.ReadAll(r)
+	}
+	// Leave room for the final read that detects EOF, so the buffer is not grown for it.
+	buf := bytes.NewBuffer(make([]byte, 0, size+bytes.MinRead))
+	_, err := buf.ReadFrom(r)
+	return buf.Bytes(), err
+}
+
+// countingReader counts the bytes read from r and reports them to fn, if not nil.
+type countingReader struct {
+	r     io.Reader
+	read  int64
+	total int64
+	fn    func(bytesRead, totalBytes int64)
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	if n > 0 {
+		c.read += int64(n)
+		if c.fn != nil {
+			c.fn(c.read, c.total)
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,1237 @@
+package geozip_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ngrash/geozip"
+	"github.com/ngrash/geozip/geoziptest"
+)
+
+func TestClient_FetchCountry(t *testing.T) {
+	client := &geozip.Client{
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				if got, want := r.URL.String(), "https://download.geonames.org/export/zip/DE.zip"; got != want {
+					t.Errorf("client requested %q, want %q", got, want)
+				}
+				file, err := os.Open("test_data/DE.zip")
+				if err != nil {
+					t.Fatal("open test data", err)
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       file,
+					Header: http.Header{
+						"Etag": []string{`"new_etag"`},
+					},
+				}, nil
+			}),
+		},
+	}
+	// Make sure the global client is not used.
+	geozip.HTTPClient.Transport = RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		t.Error("global HTTPClient used")
+		return &http.Response{StatusCode: http.StatusInternalServerError}, nil
+	})
+	defer func() { geozip.HTTPClient.Transport = nil }()
+
+	entries, modified, newEtag, err := client.FetchCountry("de", "")
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if got, want := len(entries), 16477; got != want {
+		t.Errorf("len(entries) = %v, want %v", got, want)
+	}
+	if !modified {
+		t.Error("modified = false, want true")
+	}
+	if got, want := newEtag, `"new_etag"`; got != want {
+		t.Errorf("newEtag = %v, want %v", got, want)
+	}
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	transport, ok := geozip.NewHTTPClient().Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("Transport is not an *http.Transport")
+	}
+	if got, want := transport.MaxIdleConnsPerHost, 2*geozip.DefaultConcurrency; got != want {
+		t.Errorf("MaxIdleConnsPerHost = %v, want %v", got, want)
+	}
+	if transport == http.DefaultTransport {
+		t.Error("Transport is http.DefaultTransport, want a clone")
+	}
+}
+
+func TestNewHTTPClient_WrappedDefaultTransport(t *testing.T) {
+	prev := http.DefaultTransport
+	http.DefaultTransport = RoundTripperFunc(prev.RoundTrip)
+	defer func() { http.DefaultTransport = prev }()
+
+	transport, ok := geozip.NewHTTPClient().Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("Transport is not an *http.Transport")
+	}
+	if got, want := transport.MaxIdleConnsPerHost, 2*geozip.DefaultConcurrency; got != want {
+		t.Errorf("MaxIdleConnsPerHost = %v, want %v", got, want)
+	}
+	if transport.Proxy == nil {
+		t.Error("Proxy = nil, want proxy settings from the environment")
+	}
+}
+
+func TestClient_FetchCountryResult(t *testing.T) {
+	data, err := os.ReadFile("test_data/DE.zip")
+	if err != nil {
+		t.Fatal("read test data", err)
+	}
+	client := &geozip.Client{
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode:    http.StatusOK,
+					Body:          io.NopCloser(bytes.NewReader(data)),
+					ContentLength: int64(len(data)),
+					Header: http.Header{
+						"Etag":          []string{`"new_etag"`},
+						"Last-Modified": []string{"Thu, 21 Dec 2023 03:15:00 GMT"},
+					},
+				}, nil
+			}),
+		},
+	}
+
+	res, err := client.FetchCountryResult("de", "")
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if got, want := len(res.Entries), 16477; got != want {
+		t.Errorf("len(res.Entries) = %v, want %v", got, want)
+	}
+	if !res.Modified {
+		t.Error("res.Modified = false, want true")
+	}
+	if got, want := res.ETag, `"new_etag"`; got != want {
+		t.Errorf("res.ETag = %v, want %v", got, want)
+	}
+	if got, want := res.LastModified, time.Date(2023, 12, 21, 3, 15, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("res.LastModified = %v, want %v", got, want)
+	}
+	if got, want := res.ContentLength, int64(len(data)); got != want {
+		t.Errorf("res.ContentLength = %v, want %v", got, want)
+	}
+}
+
+func TestClient_FetchCountryResult_NoLastModified(t *testing.T) {
+	data, err := os.ReadFile("test_data/DE.zip")
+	if err != nil {
+		t.Fatal("read test data", err)
+	}
+	client := &geozip.Client{HTTPClient: &http.Client{Transport: serveBytes(data, `"new_etag"`)}}
+
+	res, err := client.FetchCountryResult("de", "")
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if !res.LastModified.IsZero() {
+		t.Errorf("res.LastModified = %v, want zero time", res.LastModified)
+	}
+}
+
+func TestClient_FetchCountry_GzipContentEncoding(t *testing.T) {
+	data, err := os.ReadFile("test_data/DE.zip")
+	if err != nil {
+		t.Fatal("read test data", err)
+	}
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	if _, err := gz.Write(data); err != nil {
+		t.Fatal("gzip test data", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal("gzip test data", err)
+	}
+
+	client := &geozip.Client{
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewReader(gzipped.Bytes())),
+					Header: http.Header{
+						"Content-Encoding": []string{"gzip"},
+						"Etag":             []string{`"new_etag"`},
+					},
+				}, nil
+			}),
+		},
+	}
+
+	entries, _, _, err := client.FetchCountry("de", "")
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if got, want := len(entries), 16477; got != want {
+		t.Errorf("len(entries) = %v, want %v", got, want)
+	}
+}
+
+func TestClient_BaseURL(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		want    string
+	}{
+		{"", "https://download.geonames.org/export/zip/DE.zip"},
+		{"https://mirror.example.com/geonames", "https://mirror.example.com/geonames/DE.zip"},
+		{"https://mirror.example.com/geonames/", "https://mirror.example.com/geonames/DE.zip"},
+		{"https://mirror.example.com//", "https://mirror.example.com/DE.zip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.baseURL, func(t *testing.T) {
+			client := &geozip.Client{
+				BaseURL: tt.baseURL,
+				HTTPClient: &http.Client{
+					Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+						if got := r.URL.String(); got != tt.want {
+							t.Errorf("client requested %q, want %q", got, tt.want)
+						}
+						return &http.Response{StatusCode: http.StatusNotModified}, nil
+					}),
+				},
+			}
+			if _, _, _, err := client.FetchCountry("DE", `"etag"`); err != nil {
+				t.Errorf("err = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestClient_MaxBytes(t *testing.T) {
+	data, err := os.ReadFile("test_data/DE.zip")
+	if err != nil {
+		t.Fatal("read test data", err)
+	}
+	serve := serveBytes(data, `"etag"`)
+	tests := []struct {
+		name          string
+		maxBytes      int64
+		contentLength int64
+		wantErr       bool
+	}{
+		{"unlimited", 0, -1, false},
+		{"exact", int64(len(data)), -1, false},
+		{"exceeded", int64(len(data)) - 1, -1, true},
+		{"exceeded Content-Length", int64(len(data)) - 1, int64(len(data)), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			client := &geozip.Client{
+				MaxBytes:    tt.maxBytes,
+				RetryPolicy: geozip.RetryPolicy{MaxRetries: 2},
+				HTTPClient: &http.Client{
+					Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+						attempts++
+						resp, err := serve(r)
+						resp.ContentLength = tt.contentLength
+						return resp, err
+					}),
+				},
+			}
+
+			_, _, _, err := client.FetchCountry("DE", "")
+			if tt.wantErr {
+				if !errors.Is(err, geozip.ErrResponseTooLarge) {
+					t.Errorf("err = %v, want %v", err, geozip.ErrResponseTooLarge)
+				}
+				if attempts != 1 {
+					t.Errorf("%d attempts, want 1", attempts)
+				}
+			} else if err != nil {
+				t.Errorf("err = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestClient_FallbackURLs(t *testing.T) {
+	data := zipArchive(t, map[string]string{"DE.txt": "DE\t54668\tFerschweiler\n"})
+	serve := serveBytes(data, `"etag"`)
+	var requested []string
+	client := &geozip.Client{
+		BaseURL:      "https://mirror.example.com/zip",
+		FallbackURLs: []string{"https://broken.example.com/zip", geozip.DefaultBaseURL},
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				requested = append(requested, r.URL.String())
+				switch r.URL.Host {
+				case "mirror.example.com":
+					return nil, errors.New("connection refused")
+				case "broken.example.com":
+					return &http.Response{StatusCode: http.StatusBadGateway, Status: "502 Bad Gateway"}, nil
+				default:
+					return serve(r)
+				}
+			}),
+		},
+	}
+
+	entries, modified, _, err := client.FetchCountry("DE", "")
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if !modified || len(entries) != 1 {
+		t.Errorf("modified, len(entries) = %v, %v, want true, 1", modified, len(entries))
+	}
+	want := []string{
+		"https://mirror.example.com/zip/DE.zip",
+		"https://broken.example.com/zip/DE.zip",
+		"https://download.geonames.org/export/zip/DE.zip",
+	}
+	if got := strings.Join(requested, " "); got != strings.Join(want, " ") {
+		t.Errorf("requested %v, want %v", got, want)
+	}
+}
+
+func TestClient_FetchCountryResult_Source(t *testing.T) {
+	data := zipArchive(t, map[string]string{"postal.txt": "DE\t54668\tFerschweiler\n"})
+	serve := serveBytes(data, `"etag"`)
+	client := &geozip.Client{
+		BaseURL:      "https://mirror.example.com/zip",
+		FallbackURLs: []string{geozip.DefaultBaseURL},
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				if r.URL.Host == "mirror.example.com" {
+					return nil, errors.New("connection refused")
+				}
+				if r.Header.Get("If-None-Match") == `"etag"` {
+					return &http.Response{StatusCode: http.StatusNotModified, Body: http.NoBody}, nil
+				}
+				return serve(r)
+			}),
+		},
+	}
+
+	res, err := client.FetchCountryResult("DE", "")
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if want := "https://download.geonames.org/export/zip/DE.zip"; res.URL != want {
+		t.Errorf("URL = %q, want %q", res.URL, want)
+	}
+	if want := "postal.txt"; res.Member != want {
+		t.Errorf("Member = %q, want %q", res.Member, want)
+	}
+
+	res, err = client.FetchCountryResult("DE", `"etag"`)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if res.Modified || res.Member != "" {
+		t.Errorf("Modified, Member = %v, %q, want false, \"\"", res.Modified, res.Member)
+	}
+	if want := "https://download.geonames.org/export/zip/DE.zip"; res.URL != want {
+		t.Errorf("URL = %q, want %q", res.URL, want)
+	}
+}
+
+func TestClient_FallbackURLs_AllFail(t *testing.T) {
+	client := &geozip.Client{
+		FallbackURLs: []string{"https://mirror.example.com/zip"},
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusServiceUnavailable, Status: "503 Service Unavailable"}, nil
+			}),
+		},
+	}
+
+	_, _, _, err := client.FetchCountry("DE", "")
+	if err == nil {
+		t.Fatal("err = nil, want error")
+	}
+	if got, want := err.Error(), "status = 503 Service Unavailable, want 200\n"+
+		"fallback https://mirror.example.com/zip: status = 503 Service Unavailable, want 200"; got != want {
+		t.Errorf("err = %q, want %q", got, want)
+	}
+}
+
+func TestClient_FallbackURLs_NonRetriableServerError(t *testing.T) {
+	data := zipArchive(t, map[string]string{"DE.txt": "DE\t54668\tFerschweiler\n"})
+	serve := serveBytes(data, `"etag"`)
+	for _, code := range []int{http.StatusNotImplemented, http.StatusHTTPVersionNotSupported, http.StatusInsufficientStorage} {
+		var requested []string
+		client := &geozip.Client{
+			BaseURL:      "https://broken.example.com/zip",
+			FallbackURLs: []string{geozip.DefaultBaseURL},
+			HTTPClient: &http.Client{
+				Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+					requested = append(requested, r.URL.Host)
+					if r.URL.Host == "broken.example.com" {
+						return &http.Response{StatusCode: code, Status: http.StatusText(code)}, nil
+					}
+					return serve(r)
+				}),
+			},
+		}
+
+		entries, _, _, err := client.FetchCountry("DE", "")
+		if err != nil || len(entries) != 1 {
+			t.Errorf("status %d: len(entries), err = %v, %v, want 1, nil", code, len(entries), err)
+		}
+		if got, want := len(requested), 2; got != want {
+			t.Errorf("status %d: %d requests, want %d", code, got, want)
+		}
+	}
+}
+
+func TestClient_FallbackURLs_NotFound(t *testing.T) {
+	client := &geozip.Client{
+		FallbackURLs: []string{"https://mirror.example.com/zip"},
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				if r.URL.Host == "mirror.example.com" {
+					t.Error("fallback used after 404")
+				}
+				return &http.Response{StatusCode: http.StatusNotFound, Status: "404 Not Found"}, nil
+			}),
+		},
+	}
+
+	if _, _, _, err := client.FetchCountry("AQ", ""); !errors.Is(err, geozip.ErrCountryNotFound) {
+		t.Errorf("err = %v, want %v", err, geozip.ErrCountryNotFound)
+	}
+}
+
+func TestClient_FetchCountryFiltered(t *testing.T) {
+	data, err := os.ReadFile("test_data/DE.zip")
+	if err != nil {
+		t.Fatal("read test data", err)
+	}
+	client := &geozip.Client{HTTPClient: &http.Client{Transport: serveBytes(data, `"new_etag"`)}}
+
+	entries, modified, newEtag, err := client.FetchCountryFiltered("de", "", func(e geozip.Entry) bool {
+		return e[geozip.AdminCode3] == "07232"
+	})
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if !modified {
+		t.Error("modified = false, want true")
+	}
+	if got, want := newEtag, `"new_etag"`; got != want {
+		t.Errorf("newEtag = %v, want %v", got, want)
+	}
+	if len(entries) == 0 || len(entries) >= 16477 {
+		t.Fatalf("len(entries) = %v, want a proper subset", len(entries))
+	}
+	for i, e := range entries {
+		if got, want := e[geozip.AdminCode3], "07232"; got != want {
+			t.Errorf("entries[%d]: AdminCode3 = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestClient_FetchCountryMember(t *testing.T) {
+	data := zipArchive(t, map[string]string{
+		"DE.txt":     "DE\t54668\tFerschweiler\n",
+		"mirror.txt": "DE\t56479\tNeustadt (Westerwald)\n",
+	})
+	client := &geozip.Client{HTTPClient: &http.Client{Transport: serveBytes(data, `"new_etag"`)}}
+
+	for member, want := range map[string]string{
+		"mirror.txt": "Neustadt (Westerwald)",
+		"":           "Ferschweiler",
+	} {
+		entries, modified, newEtag, err := client.FetchCountryMember("DE", "", member)
+		if err != nil {
+			t.Fatalf("member %q: err = %v, want nil", member, err)
+		}
+		if !modified || newEtag != `"new_etag"` {
+			t.Errorf("member %q: modified, newEtag = %v, %v, want true, new_etag", member, modified, newEtag)
+		}
+		if len(entries) != 1 || entries[0][geozip.PlaceName] != want {
+			t.Errorf("member %q: entries = %v, want a single entry for %v", member, entries, want)
+		}
+	}
+}
+
+func TestClient_FetchCountryMember_Missing(t *testing.T) {
+	// The single data member would be used as a fallback for the default member, but not for a named one.
+	data := zipArchive(t, map[string]string{"mirror.txt": "DE\t54668\tFerschweiler\n"})
+	client := &geozip.Client{HTTPClient: &http.Client{Transport: serveBytes(data, `"etag"`)}}
+
+	entries, _, _, err := client.FetchCountryMember("DE", "", "typo.txt")
+	if !errors.Is(err, geozip.ErrMemberNotFound) {
+		t.Errorf("err = %v, want %v", err, geozip.ErrMemberNotFound)
+	}
+	if entries != nil {
+		t.Errorf("entries = %v, want nil", entries)
+	}
+	if _, _, _, err := client.FetchCountryMember("DE", "", ""); err != nil {
+		t.Errorf("default member: err = %v, want nil", err)
+	}
+}
+
+func TestClient_MemberPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		members map[string]string
+		want    string
+	}{
+		{"DE*.txt", map[string]string{"readme.txt": "", "DE_2024.txt": "DE\t54668\tFerschweiler\n"}, "Ferschweiler"},
+		{"*.txt", map[string]string{"readme.txt": "", "DE_2024.txt": "DE\t54668\tFerschweiler\n"}, "Ferschweiler"},
+		{"*.txt", map[string]string{"DE.txt": "", "DE_2024.txt": ""}, ""},
+		{"*.csv", map[string]string{"DE.txt": "DE\t54668\tFerschweiler\n"}, ""},
+	}
+	for _, tt := range tests {
+		data := zipArchive(t, tt.members)
+		client := &geozip.Client{
+			MemberPattern: tt.pattern,
+			HTTPClient:    &http.Client{Transport: serveBytes(data, `"etag"`)},
+		}
+
+		entries, _, _, err := client.FetchCountry("DE", "")
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("pattern %q with members %v: err = nil, want error", tt.pattern, tt.members)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("pattern %q: err = %v, want nil", tt.pattern, err)
+		}
+		if len(entries) != 1 || entries[0][geozip.PlaceName] != tt.want {
+			t.Errorf("pattern %q: entries = %v, want a single entry for %v", tt.pattern, entries, tt.want)
+		}
+	}
+}
+
+func TestClient_FetchCountryRaw(t *testing.T) {
+	const txt = "DE\t54668\tFerschweiler\n"
+	var downloads int
+	data := zipArchive(t, map[string]string{"DE.txt": txt})
+	client := &geozip.Client{HTTPClient: &http.Client{Transport: etagServer(t, data, `"etag"`, &downloads)}}
+
+	raw, modified, newEtag, err := client.FetchCountryRaw("de", "")
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if got, want := string(raw), txt; got != want {
+		t.Errorf("data = %q, want %q", got, want)
+	}
+	if !modified {
+		t.Error("modified = false, want true")
+	}
+	if got, want := newEtag, `"etag"`; got != want {
+		t.Errorf("newEtag = %v, want %v", got, want)
+	}
+
+	raw, modified, newEtag, err = client.FetchCountryRaw("de", `"etag"`)
+	if err != nil {
+		t.Fatalf("not modified: err = %v, want nil", err)
+	}
+	if raw != nil || modified || newEtag != `"etag"` {
+		t.Errorf("not modified: data, modified, newEtag = %q, %v, %v, want nil, false, etag", raw, modified, newEtag)
+	}
+}
+
+func TestClient_FetchMember(t *testing.T) {
+	const readme = "GeoNames Postal Code files ... Creative Commons Attribution 4.0 License"
+	data := zipArchive(t, map[string]string{
+		"readme.txt": readme,
+		"DE.txt":     "DE\t54668\tFerschweiler\n",
+	})
+	client := &geozip.Client{HTTPClient: &http.Client{Transport: serveBytes(data, `"etag"`)}}
+
+	got, modified, newEtag, err := client.FetchMember("DE", "", "readme.txt")
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if string(got) != readme || !modified || newEtag != `"etag"` {
+		t.Errorf("data, modified, newEtag = %q, %v, %v, want %q, true, etag", got, modified, newEtag, readme)
+	}
+
+	if _, _, _, err := client.FetchMember("DE", "", "LICENSE.txt"); err == nil {
+		t.Error("missing member: err = nil, want error")
+	}
+}
+
+func TestClient_CountCountry(t *testing.T) {
+	data, err := os.ReadFile("test_data/DE.zip")
+	if err != nil {
+		t.Fatal("read test data", err)
+	}
+	var downloads int
+	client := &geozip.Client{HTTPClient: &http.Client{Transport: etagServer(t, data, `"etag"`, &downloads)}}
+
+	count, modified, newEtag, err := client.CountCountry("DE", "")
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if got, want := count, 16477; got != want {
+		t.Errorf("count = %v, want %v", got, want)
+	}
+	if !modified || newEtag != `"etag"` {
+		t.Errorf("modified, newEtag = %v, %v, want true, etag", modified, newEtag)
+	}
+
+	count, modified, _, err = client.CountCountry("DE", `"etag"`)
+	if err != nil || count != 0 || modified {
+		t.Errorf("not modified: count, modified, err = %v, %v, %v, want 0, false, nil", count, modified, err)
+	}
+}
+
+func TestClient_CountCountry_NoTrailingNewline(t *testing.T) {
+	data := zipArchive(t, map[string]string{"DE.txt": "DE\t54668\tFerschweiler\r\n\nDE\t56479\tRehe"})
+	client := &geozip.Client{HTTPClient: &http.Client{Transport: serveBytes(data, `"etag"`)}}
+
+	count, _, _, err := client.CountCountry("DE", "")
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if got, want := count, 2; got != want {
+		t.Errorf("count = %v, want %v", got, want)
+	}
+}
+
+// closeErrorBody is a response body whose Close method fails.
+type closeErrorBody struct {
+	io.Reader
+	err error
+}
+
+func (b closeErrorBody) Close() error {
+	return b.err
+}
+
+func TestClient_FetchCountry_CloseError(t *testing.T) {
+	errClose := errors.New("close failed")
+	client := &geozip.Client{
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusForbidden,
+					Status:     "403 Forbidden",
+					Body:       closeErrorBody{Reader: strings.NewReader(""), err: errClose},
+				}, nil
+			}),
+		},
+	}
+
+	_, _, _, err := client.FetchCountry("DE", "")
+	if !errors.Is(err, errClose) {
+		t.Errorf("err = %v, want it to include %v", err, errClose)
+	}
+	if !strings.Contains(err.Error(), "403 Forbidden") {
+		t.Errorf("err = %v, want it to include the status", err)
+	}
+}
+
+// trackingBody records whether it has been read to EOF and closed.
+type trackingBody struct {
+	r      io.Reader
+	eof    bool
+	closed bool
+}
+
+func (b *trackingBody) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if err == io.EOF {
+		b.eof = true
+	}
+	return n, err
+}
+
+func (b *trackingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestClient_FetchCountry_NotModifiedWithBody(t *testing.T) {
+	body := &trackingBody{r: strings.NewReader("unexpected body")}
+	client := &geozip.Client{
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusNotModified, Body: body}, nil
+			}),
+		},
+	}
+
+	_, modified, _, err := client.FetchCountry("DE", `"etag"`)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if modified {
+		t.Error("modified = true, want false")
+	}
+	if !body.eof {
+		t.Error("body not drained")
+	}
+	if !body.closed {
+		t.Error("body not closed")
+	}
+}
+
+func TestClient_FetchCountryIfModified(t *testing.T) {
+	lastModified := time.Date(2023, 12, 21, 3, 15, 0, 0, time.UTC)
+	client := &geozip.Client{
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				if got, want := r.Header.Get("If-Modified-Since"), "Thu, 21 Dec 2023 03:15:00 GMT"; got != want {
+					t.Errorf("client sent If-Modified-Since = %q, want %q", got, want)
+				}
+				return &http.Response{StatusCode: http.StatusNotModified}, nil
+			}),
+		},
+	}
+
+	res, err := client.FetchCountryIfModified("DE", "", lastModified.In(time.FixedZone("CET", 3600)))
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if res.Modified {
+		t.Error("res.Modified = true, want false")
+	}
+	if res.Entries != nil {
+		t.Errorf("res.Entries = %v, want nil", res.Entries)
+	}
+	if !res.LastModified.Equal(lastModified) {
+		t.Errorf("res.LastModified = %v, want %v", res.LastModified, lastModified)
+	}
+}
+
+func TestClient_FetchCountryIfModified_ZeroTime(t *testing.T) {
+	client := &geozip.Client{
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				if _, ok := r.Header["If-Modified-Since"]; ok {
+					t.Error("client sent If-Modified-Since, want none")
+				}
+				return &http.Response{StatusCode: http.StatusNotModified}, nil
+			}),
+		},
+	}
+
+	if _, err := client.FetchCountryIfModified("DE", `"etag"`, time.Time{}); err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+}
+
+func BenchmarkClient_FetchCountry(b *testing.B) {
+	data, err := os.ReadFile("test_data/DE.zip")
+	if err != nil {
+		b.Fatal("read test data", err)
+	}
+	client := &geozip.Client{
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode:    http.StatusOK,
+					Body:          io.NopCloser(bytes.NewReader(data)),
+					ContentLength: int64(len(data)),
+				}, nil
+			}),
+		},
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, _, _, err := client.FetchCountry("DE", ""); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestClient_FetchCountryTimeout(t *testing.T) {
+	client := &geozip.Client{
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				<-r.Context().Done()
+				return nil, r.Context().Err()
+			}),
+		},
+	}
+
+	_, err := client.FetchCountryTimeout("DE", "", time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestClient_FetchCountryTimeout_Zero(t *testing.T) {
+	client := &geozip.Client{
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				if _, ok := r.Context().Deadline(); ok {
+					t.Error("request has deadline, want none")
+				}
+				return &http.Response{StatusCode: http.StatusNotModified}, nil
+			}),
+		},
+	}
+
+	if _, err := client.FetchCountryTimeout("DE", `"etag"`, 0); err != nil {
+		t.Errorf("err = %v, want nil", err)
+	}
+}
+
+func TestClient_FetchCountryContext_CancelDuringRetry(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	client := &geozip.Client{
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				cancel()
+				return &http.Response{StatusCode: http.StatusServiceUnavailable}, nil
+			}),
+		},
+		RetryPolicy: geozip.RetryPolicy{MaxRetries: 1, BaseDelay: time.Hour},
+	}
+
+	_, err := client.FetchCountryContext(ctx, "DE", "")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestClient_RequestModifier(t *testing.T) {
+	client := &geozip.Client{
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				user, pass, ok := r.BasicAuth()
+				if !ok || user != "user" || pass != "secret" {
+					t.Errorf("client sent basic auth %q:%q (ok = %v), want user:secret", user, pass, ok)
+				}
+				if got, want := r.Header.Get("X-Mirror-Token"), "token"; got != want {
+					t.Errorf("client sent X-Mirror-Token = %q, want %q", got, want)
+				}
+				if got, want := r.Header.Get("If-None-Match"), "overridden"; got != want {
+					t.Errorf("client sent If-None-Match = %q, want %q", got, want)
+				}
+				return &http.Response{StatusCode: http.StatusNotModified}, nil
+			}),
+		},
+		RequestModifier: func(r *http.Request) {
+			r.SetBasicAuth("user", "secret")
+			r.Header.Set("X-Mirror-Token", "token")
+			r.Header.Set("If-None-Match", "overridden")
+		},
+	}
+
+	if _, _, _, err := client.FetchCountry("DE", `"etag"`); err != nil {
+		t.Errorf("err = %v, want nil", err)
+	}
+}
+
+func TestClient_OnRequest(t *testing.T) {
+	data := zipArchive(t, map[string]string{"DE.txt": "DE\t54668\tFerschweiler\n"})
+	serve := serveBytes(data, `"etag"`)
+	attempts := 0
+	var infos []geozip.RequestInfo
+	client := &geozip.Client{
+		RetryPolicy: geozip.RetryPolicy{MaxRetries: 1},
+		OnRequest:   func(info geozip.RequestInfo) { infos = append(infos, info) },
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				attempts++
+				if attempts == 1 {
+					return &http.Response{StatusCode: http.StatusServiceUnavailable, Status: "503 Service Unavailable"}, nil
+				}
+				return serve(r)
+			}),
+		},
+	}
+
+	if _, _, _, err := client.FetchCountry("DE", ""); err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if got, want := len(infos), 2; got != want {
+		t.Fatalf("OnRequest called %d times, want %d", got, want)
+	}
+	if got := infos[0]; got.StatusCode != http.StatusServiceUnavailable || got.Err == nil || got.Modified {
+		t.Errorf("first request: %+v, want status 503, an error and not modified", got)
+	}
+	got := infos[1]
+	if got.Method != http.MethodGet || got.URL != "https://download.geonames.org/export/zip/DE.zip" ||
+		got.StatusCode != http.StatusOK || got.Bytes != int64(len(data)) || !got.Modified || got.Err != nil {
+		t.Errorf("second request: %+v, want successful GET of DE.zip with %d bytes", got, len(data))
+	}
+}
+
+func TestClient_Progress(t *testing.T) {
+	data, err := os.ReadFile("test_data/DE.zip")
+	if err != nil {
+		t.Fatal("read test data", err)
+	}
+	var calls int
+	var lastRead, lastTotal int64
+	client := &geozip.Client{
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode:    http.StatusOK,
+					Body:          io.NopCloser(bytes.NewReader(data)),
+					ContentLength: int64(len(data)),
+				}, nil
+			}),
+		},
+		Progress: func(bytesRead, totalBytes int64) {
+			if bytesRead <= lastRead {
+				t.Errorf("bytesRead = %d after %d, want increasing", bytesRead, lastRead)
+			}
+			calls++
+			lastRead, lastTotal = bytesRead, totalBytes
+		},
+	}
+
+	if _, _, _, err := client.FetchCountry("DE", ""); err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if calls == 0 {
+		t.Fatal("Progress not called")
+	}
+	if got, want := lastRead, int64(len(data)); got != want {
+		t.Errorf("final bytesRead = %d, want %d", got, want)
+	}
+	if got, want := lastTotal, int64(len(data)); got != want {
+		t.Errorf("totalBytes = %d, want %d", got, want)
+	}
+}
+
+func TestClient_FetchCountryInto(t *testing.T) {
+	data, err := os.ReadFile("test_data/DE.zip")
+	if err != nil {
+		t.Fatal("read test data", err)
+	}
+	var downloads int
+	client := &geozip.Client{HTTPClient: &http.Client{Transport: etagServer(t, data, `"etag"`, &downloads)}}
+
+	prefix := geozip.Entry{geozip.PlaceName: "prefix"}
+	entries, modified, newEtag, err := client.FetchCountryInto("DE", "", []geozip.Entry{prefix})
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if !modified {
+		t.Error("modified = false, want true")
+	}
+	if got, want := len(entries), 16478; got != want {
+		t.Fatalf("len(entries) = %v, want %v", got, want)
+	}
+	if entries[0] != prefix {
+		t.Errorf("entries[0] = %v, want %v", entries[0], prefix)
+	}
+
+	reused, _, _, err := client.FetchCountryInto("DE", "", entries[:0])
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if got, want := len(reused), 16477; got != want {
+		t.Fatalf("len(reused) = %v, want %v", got, want)
+	}
+	if &reused[0] != &entries[0] {
+		t.Error("capacity of dst not reused")
+	}
+
+	unchanged, modified, _, err := client.FetchCountryInto("DE", newEtag, reused)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if modified {
+		t.Error("modified = true, want false")
+	}
+	if len(unchanged) != len(reused) {
+		t.Errorf("len(unchanged) = %v, want %v", len(unchanged), len(reused))
+	}
+}
+
+func BenchmarkClient_FetchCountryInto(b *testing.B) {
+	data, err := os.ReadFile("test_data/DE.zip")
+	if err != nil {
+		b.Fatal("read test data", err)
+	}
+	client := &geozip.Client{HTTPClient: &http.Client{Transport: serveBytes(data, `"etag"`)}}
+
+	b.ReportAllocs()
+	var entries []geozip.Entry
+	for i := 0; i < b.N; i++ {
+		entries, _, _, err = client.FetchCountryInto("DE", "", entries[:0])
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestClient_FetchAllIndexed(t *testing.T) {
+	const data = "DE\t54668\tFerschweiler\nAT\t1010\tWien\nDE\t54636\tBitburg\n"
+	serve := serveBytes(zipArchive(t, map[string]string{"allCountries.txt": data}), `"etag"`)
+	client := &geozip.Client{
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				if got, want := r.URL.String(), "https://download.geonames.org/export/zip/allCountries.zip"; got != want {
+					t.Errorf("client requested %q, want %q", got, want)
+				}
+				if r.Header.Get("If-None-Match") == `"etag"` {
+					return &http.Response{StatusCode: http.StatusNotModified, Body: http.NoBody}, nil
+				}
+				return serve(r)
+			}),
+		},
+	}
+
+	idx, modified, etag, err := client.FetchAllIndexed("")
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if !modified || etag != `"etag"` {
+		t.Errorf("modified, etag = %v, %q, want true, %q", modified, etag, `"etag"`)
+	}
+	var entries []geozip.Entry
+	err = geozip.ParseStream(strings.NewReader(data), func(e geozip.Entry) error {
+		entries = append(entries, e)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := geozip.NewIndex(entries)
+	for _, code := range []string{"54668", "1010", "54636", "00000"} {
+		if got, want := idx.ByPostalCode(code), want.ByPostalCode(code); !reflect.DeepEqual(got, want) {
+			t.Errorf("ByPostalCode(%q) = %v, want %v", code, got, want)
+		}
+	}
+	if got, want := idx.ByPlaceNamePrefix(""), want.ByPlaceNamePrefix(""); !reflect.DeepEqual(got, want) {
+		t.Errorf("ByPlaceNamePrefix(\"\") = %v, want %v", got, want)
+	}
+
+	idx, modified, _, err = client.FetchAllIndexed(`"etag"`)
+	if err != nil || modified || idx != nil {
+		t.Errorf("not modified: idx, modified, err = %v, %v, %v, want nil, false, nil", idx, modified, err)
+	}
+}
+
+func BenchmarkClient_FetchAllIndexed(b *testing.B) {
+	de, err := os.ReadFile("test_data/DE.zip")
+	if err != nil {
+		b.Fatal("read test data", err)
+	}
+	text, _, _, err := (&geozip.Client{HTTPClient: &http.Client{Transport: serveBytes(de, `"etag"`)}}).FetchCountryRaw("DE", "")
+	if err != nil {
+		b.Fatal("unzip test data", err)
+	}
+	data := geoziptest.Archive(b, map[string]string{"allCountries.txt": string(text)})
+	client := &geozip.Client{HTTPClient: &http.Client{Transport: serveBytes(data, `"etag"`)}}
+
+	// heap reports the memory held by the index built last, which is what remains once fetching is done.
+	heap := func(b *testing.B, idx *geozip.Index) {
+		b.Helper()
+		var m runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&m)
+		b.ReportMetric(float64(m.HeapAlloc), "heap-B")
+		runtime.KeepAlive(idx)
+	}
+	b.Run("FetchAll+NewIndex", func(b *testing.B) {
+		b.ReportAllocs()
+		var idx *geozip.Index
+		for i := 0; i < b.N; i++ {
+			entries, _, _, err := client.FetchAll("")
+			if err != nil {
+				b.Fatal(err)
+			}
+			idx = geozip.NewIndex(entries)
+		}
+		heap(b, idx)
+	})
+	b.Run("FetchAllIndexed", func(b *testing.B) {
+		b.ReportAllocs()
+		var idx *geozip.Index
+		for i := 0; i < b.N; i++ {
+			idx, _, _, err = client.FetchAllIndexed("")
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+		heap(b, idx)
+	})
+}
+
+func TestClient_ETagNormalization(t *testing.T) {
+	data := zipArchive(t, map[string]string{"DE.txt": "DE\t54668\tFerschweiler\n"})
+	tests := []struct {
+		name     string
+		header   string
+		wantETag string
+	}{
+		{"quoted", `"abc"`, `"abc"`},
+		{"unquoted", `abc`, `"abc"`},
+		{"weak", `W/"abc"`, `W/"abc"`},
+		{"weak unquoted", `W/abc`, `W/"abc"`},
+		{"padded", ` "abc" `, `"abc"`},
+		{"missing", ``, ``},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent []string
+			serve := serveBytes(data, tt.header)
+			client := &geozip.Client{
+				HTTPClient: &http.Client{
+					Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+						sent = append(sent, r.Header.Get("If-None-Match"))
+						if r.Header.Get("If-None-Match") == tt.wantETag && tt.wantETag != "" {
+							return &http.Response{StatusCode: http.StatusNotModified, Body: http.NoBody}, nil
+						}
+						return serve(r)
+					}),
+				},
+			}
+
+			_, modified, etag, err := client.FetchCountry("DE", "")
+			if err != nil || !modified || etag != tt.wantETag {
+				t.Fatalf("first fetch: modified, etag, err = %v, %s, %v, want true, %s, nil", modified, etag, err, tt.wantETag)
+			}
+			if tt.wantETag == "" {
+				return
+			}
+
+			// Both the normalized ETag and the value as sent by the server revalidate the data.
+			for _, etag := range []string{etag, tt.header} {
+				if _, modified, _, err := client.FetchCountry("DE", etag); err != nil || modified {
+					t.Errorf("FetchCountry(%q): modified, err = %v, %v, want false, nil", etag, modified, err)
+				}
+			}
+			for i, got := range sent[1:] {
+				if got != tt.wantETag {
+					t.Errorf("request %d: If-None-Match = %s, want %s", i+2, got, tt.wantETag)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_FileURL(t *testing.T) {
+	dir := t.TempDir()
+	data := zipArchive(t, map[string]string{"DE.txt": "DE\t54668\tFerschweiler\n"})
+	if err := os.WriteFile(filepath.Join(dir, "DE.zip"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	client := &geozip.Client{
+		BaseURL: (&url.URL{Scheme: "file", Path: filepath.ToSlash(dir)}).String(),
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				t.Errorf("client requested %s over HTTP", r.URL)
+				return nil, errors.New("unexpected request")
+			}),
+		},
+	}
+
+	entries, modified, _, err := client.FetchCountry("DE", "")
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if !modified || len(entries) != 1 || entries[0][geozip.PlaceName] != "Ferschweiler" {
+		t.Errorf("modified, entries = %v, %v, want true, [Ferschweiler]", modified, entries)
+	}
+
+	// Without an ETag to compare, the data is always modified.
+	if _, modified, _, err := client.FetchCountry("DE", `"etag"`); err != nil || !modified {
+		t.Errorf("with etag: modified, err = %v, %v, want true, nil", modified, err)
+	}
+
+	if _, _, _, err := client.FetchCountry("AT", ""); !errors.Is(err, geozip.ErrCountryNotFound) {
+		t.Errorf("missing file: err = %v, want ErrCountryNotFound", err)
+	}
+}
+
+func TestClient_FileURL_Host(t *testing.T) {
+	dir := t.TempDir()
+	data := zipArchive(t, map[string]string{"DE.txt": "DE\t54668\tFerschweiler\n"})
+	if err := os.WriteFile(filepath.Join(dir, "DE.zip"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	client := &geozip.Client{BaseURL: (&url.URL{Scheme: "file", Host: "localhost", Path: filepath.ToSlash(dir)}).String()}
+	if _, _, _, err := client.FetchCountry("DE", ""); err != nil {
+		t.Errorf("localhost: err = %v, want nil", err)
+	}
+
+	// The host is not part of the path, so relative forms must not read from the file system root.
+	for _, baseURL := range []string{"file://testdata", "file://./data", "file:data"} {
+		client := &geozip.Client{BaseURL: baseURL}
+		_, _, _, err := client.FetchCountry("DE", "")
+		if err == nil || errors.Is(err, geozip.ErrCountryNotFound) || !strings.Contains(err.Error(), "absolute path") {
+			t.Errorf("%s: err = %v, want error about absolute path", baseURL, err)
+		}
+	}
+}
+
+func TestClient_FetchCountryResult_Sizes(t *testing.T) {
+	const text = "DE\t54668\tFerschweiler\nDE\t54636\tBitburg\n"
+	data := zipArchive(t, map[string]string{"DE.txt": text, "readme.txt": "license"})
+	client := &geozip.Client{HTTPClient: &http.Client{Transport: serveBytes(data, `"etag"`)}}
+
+	res, err := client.FetchCountryResult("DE", "")
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if got, want := res.CompressedBytes, int64(len(data)); got != want {
+		t.Errorf("CompressedBytes = %v, want %v", got, want)
+	}
+	if got, want := res.DecompressedBytes, int64(len(text)); got != want {
+		t.Errorf("DecompressedBytes = %v, want %v", got, want)
+	}
+}
+
+func TestClient_ConcatMembers(t *testing.T) {
+	data := zipArchive(t, map[string]string{
+		"DE_south.txt": "DE\t80331\tMünchen\n",
+		"DE_north.txt": "DE\t20095\tHamburg\nDE\t24103\tKiel\n",
+		"readme.txt":   "license",
+	})
+	tests := []struct {
+		name       string
+		concat     bool
+		pattern    string
+		wantPlaces []string
+		wantMember string
+	}{
+		{"all", true, "", []string{"Hamburg", "Kiel", "München"}, "DE_north.txt, DE_south.txt"},
+		{"pattern", true, "*_south.txt", []string{"München"}, "DE_south.txt"},
+		{"single", false, "*_north.txt", []string{"Hamburg", "Kiel"}, "DE_north.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &geozip.Client{
+				ConcatMembers: tt.concat,
+				MemberPattern: tt.pattern,
+				HTTPClient:    &http.Client{Transport: serveBytes(data, `"etag"`)},
+			}
+			res, err := client.FetchCountryResult("DE", "")
+			if err != nil {
+				t.Fatalf("err = %v, want nil", err)
+			}
+			places := make([]string, len(res.Entries))
+			for i, e := range res.Entries {
+				places[i] = e[geozip.PlaceName]
+			}
+			if !slices.Equal(places, tt.wantPlaces) {
+				t.Errorf("places = %v, want %v", places, tt.wantPlaces)
+			}
+			if res.Member != tt.wantMember {
+				t.Errorf("Member = %q, want %q", res.Member, tt.wantMember)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package geozip
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// knownCountries holds the codes of the countries for which GeoNames offered postal code data
+// as of December 2023.
+var knownCountries = []string{
+	"AD", "AR", "AS", "AT", "AU", "AX", "AZ", "BD", "BE", "BG", "BM", "BR", "BY", "CA", "CH", "CL", "CO", "CR",
+	"CY", "CZ", "DE", "DK", "DO", "DZ", "EE", "ES", "FI", "FM", "FO", "FR", "GB", "GF", "GG", "GL", "GP", "GT",
+	"GU", "HR", "HT", "HU", "IE", "IM", "IN", "IS", "IT", "JE", "JP", "KR", "LI", "LK", "LT", "LU", "LV", "MC",
+	"MD", "MH", "MK", "MP", "MQ", "MT", "MW", "MX", "MY", "NC", "NL", "NO", "NZ", "PE", "PH", "PK", "PL", "PM",
+	"PR", "PT", "PW", "RE", "RO", "RS", "RU", "SE", "SG", "SI", "SJ", "SK", "SM", "TH", "TR", "UA", "US", "UY",
+	"VA", "VI", "WF", "YT", "ZA",
+}
+
+// iso3166 holds the ISO 3166-1 alpha-2 country codes, plus XK, which GeoNames uses for Kosovo.
+var iso3166 = map[string]struct{}{
+	"AD": {}, "AE": {}, "AF": {}, "AG": {}, "AI": {}, "AL": {}, "AM": {}, "AO": {}, "AQ": {}, "AR": {},
+	"AS": {}, "AT": {}, "AU": {}, "AW": {}, "AX": {}, "AZ": {}, "BA": {}, "BB": {}, "BD": {}, "BE": {},
+	"BF": {}, "BG": {}, "BH": {}, "BI": {}, "BJ": {}, "BL": {}, "BM": {}, "BN": {}, "BO": {}, "BQ": {},
+	"BR": {}, "BS": {}, "BT": {}, "BV": {}, "BW": {}, "BY": {}, "BZ": {}, "CA": {}, "CC": {}, "CD": {},
+	"CF": {}, "CG": {}, "CH": {}, "CI": {}, "CK": {}, "CL": {}, "CM": {}, "CN": {}, "CO": {}, "CR": {},
+	"CU": {}, "CV": {}, "CW": {}, "CX": {}, "CY": {}, "CZ": {}, "DE": {}, "DJ": {}, "DK": {}, "DM": {},
+	"DO": {}, "DZ": {}, "EC": {}, "EE": {}, "EG": {}, "EH": {}, "ER": {}, "ES": {}, "ET": {}, "FI": {},
+	"FJ": {}, "FK": {}, "FM": {}, "FO": {}, "FR": {}, "GA": {}, "GB": {}, "GD": {}, "GE": {}, "GF": {},
+	"GG": {}, "GH": {}, "GI": {}, "GL": {}, "GM": {}, "GN": {}, "GP": {}, "GQ": {}, "GR": {}, "GS": {},
+	"GT": {}, "GU": {}, "GW": {}, "GY": {}, "HK": {}, "HM": {}, "HN": {}, "HR": {}, "HT": {}, "HU": {},
+	"ID": {}, "IE": {}, "IL": {}, "IM": {}, "IN": {}, "IO": {}, "IQ": {}, "IR": {}, "IS": {}, "IT": {},
+	"JE": {}, "JM": {}, "JO": {}, "JP": {}, "KE": {}, "KG": {}, "KH": {}, "KI": {}, "KM": {}, "KN": {},
+	"KP": {}, "KR": {}, "KW": {}, "KY": {}, "KZ": {}, "LA": {}, "LB": {}, "LC": {}, "LI": {}, "LK": {},
+	"LR": {}, "LS": {}, "LT": {}, "LU": {}, "LV": {}, "LY": {}, "MA": {}, "MC": {}, "MD": {}, "ME": {},
+	"MF": {}, "MG": {}, "MH": {}, "MK": {}, "ML": {}, "MM": {}, "MN": {}, "MO": {}, "MP": {}, "MQ": {},
+	"MR": {}, "MS": {}, "MT": {}, "MU": {}, "MV": {}, "MW": {}, "MX": {}, "MY": {}, "MZ": {}, "NA": {},
+	"NC": {}, "NE": {}, "NF": {}, "NG": {}, "NI": {}, "NL": {}, "NO": {}, "NP": {}, "NR": {}, "NU": {},
+	"NZ": {}, "OM": {}, "PA": {}, "PE": {}, "PF": {}, "PG": {}, "PH": {}, "PK": {}, "PL": {}, "PM": {},
+	"PN": {}, "PR": {}, "PS": {}, "PT": {}, "PW": {}, "PY": {}, "QA": {}, "RE": {}, "RO": {}, "RS": {},
+	"RU": {}, "RW": {}, "SA": {}, "SB": {}, "SC": {}, "SD": {}, "SE": {}, "SG": {}, "SH": {}, "SI": {},
+	"SJ": {}, "SK": {}, "SL": {}, "SM": {}, "SN": {}, "SO": {}, "SR": {}, "SS": {}, "ST": {}, "SV": {},
+	"SX": {}, "SY": {}, "SZ": {}, "TC": {}, "TD": {}, "TF": {}, "TG": {}, "TH": {}, "TJ": {}, "TK": {},
+	"TL": {}, "TM": {}, "TN": {}, "TO": {}, "TR": {}, "TT": {}, "TV": {}, "TW": {}, "TZ": {}, "UA": {},
+	"UG": {}, "UM": {}, "US": {}, "UY": {}, "UZ": {}, "VA": {}, "VC": {}, "VE": {}, "VG": {}, "VI": {},
+	"VN": {}, "VU": {}, "WF": {}, "WS": {}, "YE": {}, "YT": {}, "ZA": {}, "ZM": {}, "ZW": {},
+	"XK": {},
+}
+
+// KnownCountries returns the sorted codes of the countries for which GeoNames offered postal code data
+// when this package was released. Unlike AvailableCountries, it does not make a network request,
+// but the list may be outdated.
+func KnownCountries() []string {
+	return append([]string(nil), knownCountries...)
+}
+
+// AvailableCountries returns the sorted codes of the countries for which GeoNames currently offers postal code data.
+// It fetches and parses the directory listing of the download server.
+// Use KnownCountries to avoid the network request.
+func AvailableCountries() ([]string, error) {
+	return defaultClient.AvailableCountries()
+}
+
+// CountryExists reports whether GeoNames offers postal code data for the given country code, without downloading it.
+// It issues a HEAD request for the country's archive, which exists if the server responds with 200 OK.
+// An invalid country code is reported as an error, as for FetchCountry.
+func CountryExists(cc string) (bool, error) {
+	return defaultClient.CountryExists(cc)
+}
+
+// CountryExists reports whether the client's download server offers postal code data for the given country code.
+// See the package-level CountryExists for details.
+func (c *Client) CountryExists(cc string) (bool, error) {
+	cc, err := normalizeCountryCode(cc)
+	if err != nil {
+		return false, err
+	}
+
+	ctx := context.Background()
+	var exists bool
+	err = c.RetryPolicy.do(ctx, func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, c.downloadURL(cc), nil)
+		if err != nil {
+			return err
+		}
+		start := time.Now()
+		resp, err := c.do(req)
+		if err != nil {
+			c.logRequest(RequestInfo{Method: req.Method, URL: req.URL.String(), Duration: time.Since(start), Err: err})
+			return err
+		}
+		resp.Body.Close()
+		c.logRequest(RequestInfo{Method: req.Method, URL: req.URL.String(), StatusCode: resp.StatusCode, Duration: time.Since(start)})
+
+		switch {
+		case resp.StatusCode == http.StatusOK:
+			exists = true
+			return nil
+		case resp.StatusCode == http.StatusNotFound:
+			exists = false
+			return nil
+		case retriableStatus(resp.StatusCode):
+			return &retriableError{err: &statusError{code: resp.StatusCode, status: resp.Status}, retryAfter: retryAfter(resp)}
+		default:
+			return &statusError{code: resp.StatusCode, status: resp.Status}
+		}
+	})
+	if err != nil {
+		return false, fmt.Errorf("check %s: %w", cc, err)
+	}
+	return exists, nil
+}
+
+// countryArchive matches links to per-country archives in the directory listing.
+var countryArchive = regexp.MustCompile(`href="([A-Z]{2})\.zip"`)
+
+// AvailableCountries returns the sorted codes of the countries for which the client's download server
+// offers postal code data. See the package-level AvailableCountries for details.
+func (c *Client) AvailableCountries() ([]string, error) {
+	resp, err := c.download(context.Background(), c.baseURL()+"/", validators{})
+	if err != nil {
+		return nil, fmt.Errorf("fetch directory listing: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var ccs []string
+	for _, m := range countryArchive.FindAllSubmatch(resp.body, -1) {
+		cc := string(m[1])
+		if !seen[cc] {
+			seen[cc] = true
+			ccs = append(ccs, cc)
+		}
+	}
+	sort.Strings(ccs)
+	return ccs, nil
+}
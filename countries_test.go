@@ -0,0 +1,110 @@
+package geozip_test
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/ngrash/geozip"
+)
+
+func TestClient_AvailableCountries(t *testing.T) {
+	const listing = `<html><body><pre>
+<a href="AD.zip">AD.zip</a>
+<a href="DE.zip">DE.zip</a>
+<a href="GB_full.csv.zip">GB_full.csv.zip</a>
+<a href="allCountries.zip">allCountries.zip</a>
+<a href="AT.zip">AT.zip</a>
+<a href="readme.txt">readme.txt</a>
+</pre></body></html>`
+	serve := serveBytes([]byte(listing), "")
+	client := &geozip.Client{
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				if got, want := r.URL.String(), "https://download.geonames.org/export/zip/"; got != want {
+					t.Errorf("client requested %q, want %q", got, want)
+				}
+				return serve(r)
+			}),
+		},
+	}
+
+	ccs, err := client.AvailableCountries()
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if got, want := ccs, []string{"AD", "AT", "DE"}; !slices.Equal(got, want) {
+		t.Errorf("AvailableCountries() = %v, want %v", got, want)
+	}
+}
+
+func TestClient_CountryExists(t *testing.T) {
+	client := &geozip.Client{
+		BaseURL: "https://mirror.example.com/zip/",
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				if got, want := r.Method, http.MethodHead; got != want {
+					t.Errorf("method = %v, want %v", got, want)
+				}
+				switch r.URL.String() {
+				case "https://mirror.example.com/zip/DE.zip":
+					return &http.Response{StatusCode: http.StatusOK}, nil
+				case "https://mirror.example.com/zip/AQ.zip":
+					return &http.Response{StatusCode: http.StatusNotFound}, nil
+				default:
+					return &http.Response{StatusCode: http.StatusForbidden, Status: "403 Forbidden"}, nil
+				}
+			}),
+		},
+	}
+
+	for cc, want := range map[string]bool{"de": true, "AQ": false} {
+		exists, err := client.CountryExists(cc)
+		if err != nil {
+			t.Fatalf("CountryExists(%q): err = %v, want nil", cc, err)
+		}
+		if exists != want {
+			t.Errorf("CountryExists(%q) = %v, want %v", cc, exists, want)
+		}
+	}
+
+	if _, err := client.CountryExists("AT"); err == nil {
+		t.Error("CountryExists(AT): err = nil, want error for status 403")
+	}
+}
+
+func TestKnownCountries(t *testing.T) {
+	ccs := geozip.KnownCountries()
+	if !slices.IsSorted(ccs) {
+		t.Error("KnownCountries() is not sorted")
+	}
+	if !slices.Contains(ccs, "DE") {
+		t.Error("KnownCountries() does not contain DE")
+	}
+
+	ccs[0] = "XX"
+	if geozip.KnownCountries()[0] == "XX" {
+		t.Error("KnownCountries() returned shared slice")
+	}
+}
+
+func TestFetchCountry_UnknownCountryCode(t *testing.T) {
+	client := &geozip.Client{
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				t.Error("client made request, want none")
+				return &http.Response{StatusCode: http.StatusNotFound}, nil
+			}),
+		},
+	}
+
+	for _, cc := range []string{"XX", "1A", "zz"} {
+		_, _, _, err := client.FetchCountry(cc, "")
+		if err == nil {
+			t.Fatalf("FetchCountry(%q): err = nil, want error", cc)
+		}
+		if got, want := err.Error(), `unknown country code "`+cc+`"`; got != want {
+			t.Errorf("FetchCountry(%q): err = %q, want %q", cc, got, want)
+		}
+	}
+}
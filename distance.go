@@ -0,0 +1,78 @@
+package geozip
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// earthRadius is the mean Earth radius in meters.
+const earthRadius = 6371008.8
+
+// Distance returns the great-circle distance in meters between the coordinates of two entries.
+// It returns an error if either entry lacks valid coordinates.
+func Distance(a, b Entry) (float64, error) {
+	lat1, lon1, err := coordinates(a)
+	if err != nil {
+		return 0, err
+	}
+	lat2, lon2, err := coordinates(b)
+	if err != nil {
+		return 0, err
+	}
+	return DistanceLatLon(lat1, lon1, lat2, lon2), nil
+}
+
+// DistanceLatLon returns the great-circle distance in meters between two points given in degrees.
+// It uses the haversine formula with a mean Earth radius of 6371008.8 m.
+func DistanceLatLon(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	dPhi := (lat2 - lat1) * math.Pi / 180
+	dLambda := (lon2 - lon1) * math.Pi / 180
+
+	h := math.Sin(dPhi/2)*math.Sin(dPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// coordinates parses the latitude and longitude of e.
+func coordinates(e Entry) (lat, lon float64, err error) {
+	if e[Latitude] == "" || e[Longitude] == "" {
+		return 0, 0, fmt.Errorf("entry %s %s has no coordinates", e[PostalCode], e[PlaceName])
+	}
+	lat, err = strconv.ParseFloat(e[Latitude], 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("parse latitude: %w", err)
+	}
+	lon, err = strconv.ParseFloat(e[Longitude], 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("parse longitude: %w", err)
+	}
+	return lat, lon, nil
+}
+
+// Point is a position in longitude, latitude order, as used by GeoJSON and geometry libraries.
+// It has the same underlying type as orb.Point of github.com/paulmach/orb, so it converts directly:
+//
+//	p, ok := e.Point()
+//	if ok {
+//	    op := orb.Point(p)
+//	}
+type Point [2]float64
+
+// Lon returns the longitude of p.
+func (p Point) Lon() float64 { return p[0] }
+
+// Lat returns the latitude of p.
+func (p Point) Lat() float64 { return p[1] }
+
+// Point returns the coordinates of e in longitude, latitude order.
+// It returns false if e lacks valid coordinates.
+func (e Entry) Point() (Point, bool) {
+	lat, lon, err := coordinates(e)
+	if err != nil {
+		return Point{}, false
+	}
+	return Point{lon, lat}, true
+}
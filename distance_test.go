@@ -0,0 +1,69 @@
+package geozip_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ngrash/geozip"
+)
+
+func TestDistanceLatLon(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		want                   float64
+	}{
+		{"same point", 49.8667, 6.4, 49.8667, 6.4, 0},
+		{"quarter meridian", 0, 0, 90, 0, math.Pi / 2 * 6371008.8},
+		{"antipodes", 0, 0, 0, 180, math.Pi * 6371008.8},
+		{"Berlin to Munich", 52.5200, 13.4050, 48.1351, 11.5820, 504_400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := geozip.DistanceLatLon(tt.lat1, tt.lon1, tt.lat2, tt.lon2)
+			if math.Abs(got-tt.want) > 1000 {
+				t.Errorf("DistanceLatLon() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDistance(t *testing.T) {
+	a := geozip.Entry{geozip.Latitude: "0", geozip.Longitude: "0"}
+	b := geozip.Entry{geozip.Latitude: "0", geozip.Longitude: "1"}
+
+	got, err := geozip.Distance(a, b)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if want := 2 * math.Pi * 6371008.8 / 360; math.Abs(got-want) > 1e-6 {
+		t.Errorf("Distance() = %v, want %v", got, want)
+	}
+}
+
+func TestDistance_MissingCoordinates(t *testing.T) {
+	a := geozip.Entry{geozip.Latitude: "0", geozip.Longitude: "0"}
+	b := geozip.Entry{geozip.PostalCode: "12345"}
+
+	if _, err := geozip.Distance(a, b); err == nil {
+		t.Error("err = nil, want error")
+	}
+}
+
+func TestEntry_Point(t *testing.T) {
+	e := geozip.Entry{geozip.Latitude: "49.8667", geozip.Longitude: "6.4"}
+	p, ok := e.Point()
+	if !ok {
+		t.Fatal("ok = false, want true")
+	}
+	if got, want := p, (geozip.Point{6.4, 49.8667}); got != want {
+		t.Errorf("Point() = %v, want %v", got, want)
+	}
+	if p.Lon() != 6.4 || p.Lat() != 49.8667 {
+		t.Errorf("Lon(), Lat() = %v, %v, want 6.4, 49.8667", p.Lon(), p.Lat())
+	}
+
+	if _, ok := (geozip.Entry{}).Point(); ok {
+		t.Error("no coordinates: ok = true, want false")
+	}
+}
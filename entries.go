@@ -0,0 +1,236 @@
+package geozip
+
+import "sort"
+
+// Dedup returns entries without rows that are equal to a previous row in all fields, preserving the order of
+// first occurrence, along with the number of removed duplicates. The input slice is not modified.
+func Dedup(entries []Entry) (deduped []Entry, removed int) {
+	seen := make(map[Entry]struct{}, len(entries))
+	deduped = make([]Entry, 0, len(entries))
+	for _, e := range entries {
+		if _, ok := seen[e]; ok {
+			removed++
+			continue
+		}
+		seen[e] = struct{}{}
+		deduped = append(deduped, e)
+	}
+	return deduped, removed
+}
+
+// DedupBy returns entries without rows that equal a previous row in the given fields, preserving the order of
+// first occurrence, e.g. DedupBy(entries, PostalCode, PlaceName) keeps one entry per place within a postal code,
+// even if the coordinates differ slightly. If no fields are given, rows are compared in all fields, like Dedup.
+// Invalid fields are ignored. The input slice is not modified.
+func DedupBy(entries []Entry, fields ...Field) []Entry {
+	if len(fields) == 0 {
+		deduped, _ := Dedup(entries)
+		return deduped
+	}
+	seen := make(map[Entry]struct{}, len(entries))
+	deduped := make([]Entry, 0, len(entries))
+	for _, e := range entries {
+		var key Entry
+		for _, f := range fields {
+			if f.valid() {
+				key[f] = e[f]
+			}
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		deduped = append(deduped, e)
+	}
+	return deduped
+}
+
+// GroupByAdmin1 groups entries by their AdminCode1 field, preserving input order within each group.
+// Entries with an empty admin code are grouped under the empty string.
+func GroupByAdmin1(entries []Entry) map[string][]Entry {
+	return groupBy(entries, AdminCode1)
+}
+
+// GroupByAdmin2 groups entries by their AdminCode2 field, preserving input order within each group.
+// Entries with an empty admin code are grouped under the empty string.
+func GroupByAdmin2(entries []Entry) map[string][]Entry {
+	return groupBy(entries, AdminCode2)
+}
+
+func groupBy(entries []Entry, f Field) map[string][]Entry {
+	groups := make(map[string][]Entry)
+	for _, e := range entries {
+		groups[e[f]] = append(groups[e[f]], e)
+	}
+	return groups
+}
+
+// FilterByAdmin1 returns the entries whose AdminCode1 field equals code, preserving input order.
+// Codes are compared case-sensitively, as provided by GeoNames, e.g. "RP" for Rhineland-Palatinate in Germany.
+func FilterByAdmin1(entries []Entry, code string) []Entry {
+	return filterBy(entries, AdminCode1, code)
+}
+
+// FilterByAdmin2 returns the entries whose AdminCode2 field equals code, preserving input order.
+// Codes are compared case-sensitively, as provided by GeoNames.
+func FilterByAdmin2(entries []Entry, code string) []Entry {
+	return filterBy(entries, AdminCode2, code)
+}
+
+func filterBy(entries []Entry, f Field, value string) []Entry {
+	var kept []Entry
+	for _, e := range entries {
+		if e[f] == value {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+// RequireFields returns the entries in which all of the given fields are non-empty, preserving input order,
+// e.g. RequireFields(entries, PostalCode, PlaceName) drops rows lacking a postal code or place name.
+func RequireFields(entries []Entry, fields ...Field) []Entry {
+	var kept []Entry
+next:
+	for _, e := range entries {
+		for _, f := range fields {
+			if !e.Has(f) {
+				continue next
+			}
+		}
+		kept = append(kept, e)
+	}
+	return kept
+}
+
+// FilterByMinAccuracy returns the entries whose Accuracy field is at least min, preserving input order.
+// Entries with a blank or invalid accuracy are dropped, unless keepBlank is set.
+//
+// GeoNames defines the accuracy of the coordinates on a scale from 1 to 6, where higher is more precise:
+// 1 means estimated, 4 means taken from the GeoNames place (geonameid), and 6 means the centroid of addresses
+// or of the postal code area's shape. See AccuracyEstimated, AccuracyGeonameID and AccuracyCentroid.
+func FilterByMinAccuracy(entries []Entry, min int, keepBlank bool) []Entry {
+	var kept []Entry
+	for _, e := range entries {
+		a, ok := e.Accuracy()
+		if !ok {
+			if keepBlank {
+				kept = append(kept, e)
+			}
+			continue
+		}
+		if a >= min {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+// Merge concatenates multiple entry slices, e.g. of neighboring countries, into a new slice in argument order.
+// Use Dedup on the result to remove duplicate rows.
+func Merge(sets ...[]Entry) []Entry {
+	n := 0
+	for _, es := range sets {
+		n += len(es)
+	}
+	merged := make([]Entry, 0, n)
+	for _, es := range sets {
+		merged = append(merged, es...)
+	}
+	return merged
+}
+
+// MergeMap concatenates entry slices keyed by country code, e.g. collected from FetchCountries, into a new slice.
+// The slices are concatenated in order of their sorted keys, so the result is deterministic.
+func MergeMap(sets map[string][]Entry) []Entry {
+	keys := make([]string, 0, len(sets))
+	for k := range sets {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	ordered := make([][]Entry, len(keys))
+	for i, k := range keys {
+		ordered[i] = sets[k]
+	}
+	return Merge(ordered...)
+}
+
+// SplitByCountry groups entries by their CountryCode field, e.g. to store the entries fetched with FetchAll
+// per country, preserving input order within each country. It is the inverse of MergeMap.
+func SplitByCountry(entries []Entry) map[string][]Entry {
+	return groupBy(entries, CountryCode)
+}
+
+// Project returns the given fields of each entry, in the order of fields, e.g. for exporting only postal codes
+// and coordinates. If no fields are given, all fields are returned in Field order. Invalid fields yield empty
+// strings, like Entry.Get.
+func Project(entries []Entry, fields ...Field) [][]string {
+	if len(fields) == 0 {
+		fields = make([]Field, numFields)
+		for i := range fields {
+			fields[i] = Field(i)
+		}
+	}
+	rows := make([][]string, len(entries))
+	for i, e := range entries {
+		row := make([]string, len(fields))
+		for j, f := range fields {
+			row[j] = e.Get(f)
+		}
+		rows[i] = row
+	}
+	return rows
+}
+
+// Equal reports whether a and b are equal in all fields. It is equivalent to a == b.
+func Equal(a, b Entry) bool {
+	return a == b
+}
+
+// Less reports whether a sorts before b, ordering by country code, then postal code, then place name.
+// Entries that agree in these fields are ordered by their remaining fields in Field order, so that only equal
+// entries are unordered. Fields are compared as strings, byte by byte.
+func Less(a, b Entry) bool {
+	// Field order starts with CountryCode, PostalCode and PlaceName.
+	for f := range a {
+		if a[f] != b[f] {
+			return a[f] < b[f]
+		}
+	}
+	return false
+}
+
+// SortEntries sorts entries in place in the order defined by Less.
+// The order is deterministic, which makes it suitable for golden-file tests and diffs.
+func SortEntries(entries []Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return Less(entries[i], entries[j])
+	})
+}
+
+// Diff compares two versions of a dataset, e.g. before and after a fetch reported modified data.
+// It returns the entries of newer that are not in older, and the entries of older that are not in newer,
+// each in input order. Entries are compared in all fields, and duplicates are matched one to one.
+//
+// An entry that was modified in place, i.e. that keeps its country code, postal code and place name but
+// changes other fields, is reported as a removal of the old version plus an addition of the new one.
+func Diff(older, newer []Entry) (added, removed []Entry) {
+	remaining := make(map[Entry]int, len(older))
+	for _, e := range older {
+		remaining[e]++
+	}
+	for _, e := range newer {
+		if remaining[e] > 0 {
+			remaining[e]--
+			continue
+		}
+		added = append(added, e)
+	}
+	for _, e := range older {
+		if remaining[e] > 0 {
+			remaining[e]--
+			removed = append(removed, e)
+		}
+	}
+	return added, removed
+}
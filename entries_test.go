@@ -0,0 +1,247 @@
+package geozip_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ngrash/geozip"
+)
+
+func TestDedup(t *testing.T) {
+	a := geozip.Entry{geozip.PostalCode: "54668", geozip.PlaceName: "Ferschweiler"}
+	b := geozip.Entry{geozip.PostalCode: "56479", geozip.PlaceName: "Neustadt (Westerwald)"}
+	c := geozip.Entry{geozip.PostalCode: "56479", geozip.PlaceName: "Rehe"}
+
+	deduped, removed := geozip.Dedup([]geozip.Entry{b, a, b, c, a, b})
+	if got, want := deduped, []geozip.Entry{b, a, c}; !slices.Equal(got, want) {
+		t.Errorf("deduped = %v, want %v", got, want)
+	}
+	if got, want := removed, 3; got != want {
+		t.Errorf("removed = %v, want %v", got, want)
+	}
+}
+
+func TestDedupBy(t *testing.T) {
+	a := geozip.Entry{geozip.PostalCode: "56479", geozip.PlaceName: "Rehe", geozip.Latitude: "50.6333"}
+	b := geozip.Entry{geozip.PostalCode: "56479", geozip.PlaceName: "Rehe", geozip.Latitude: "50.6334"}
+	c := geozip.Entry{geozip.PostalCode: "56479", geozip.PlaceName: "Neustadt (Westerwald)"}
+	d := geozip.Entry{geozip.PostalCode: "54668", geozip.PlaceName: "Rehe"}
+	entries := []geozip.Entry{a, b, c, d, a}
+
+	tests := []struct {
+		fields []geozip.Field
+		want   []geozip.Entry
+	}{
+		{[]geozip.Field{geozip.PostalCode, geozip.PlaceName}, []geozip.Entry{a, c, d}},
+		{[]geozip.Field{geozip.PostalCode}, []geozip.Entry{a, d}},
+		{[]geozip.Field{geozip.PlaceName}, []geozip.Entry{a, c}},
+		{nil, []geozip.Entry{a, b, c, d}},
+		{[]geozip.Field{geozip.PlaceName, geozip.Field(-1)}, []geozip.Entry{a, c}},
+	}
+	for _, tt := range tests {
+		if got := geozip.DedupBy(entries, tt.fields...); !slices.Equal(got, tt.want) {
+			t.Errorf("DedupBy(%v) = %v, want %v", tt.fields, got, tt.want)
+		}
+	}
+}
+
+func TestGroupByAdmin1(t *testing.T) {
+	a := geozip.Entry{geozip.PlaceName: "a", geozip.AdminCode1: "RP"}
+	b := geozip.Entry{geozip.PlaceName: "b", geozip.AdminCode1: "BY"}
+	c := geozip.Entry{geozip.PlaceName: "c", geozip.AdminCode1: "RP"}
+	d := geozip.Entry{geozip.PlaceName: "d"}
+
+	groups := geozip.GroupByAdmin1([]geozip.Entry{a, b, c, d})
+	if got, want := len(groups), 3; got != want {
+		t.Errorf("len(groups) = %v, want %v", got, want)
+	}
+	if got, want := groups["RP"], []geozip.Entry{a, c}; !slices.Equal(got, want) {
+		t.Errorf("groups[RP] = %v, want %v", got, want)
+	}
+	if got, want := groups["BY"], []geozip.Entry{b}; !slices.Equal(got, want) {
+		t.Errorf("groups[BY] = %v, want %v", got, want)
+	}
+	if got, want := groups[""], []geozip.Entry{d}; !slices.Equal(got, want) {
+		t.Errorf("groups[\"\"] = %v, want %v", got, want)
+	}
+}
+
+func TestGroupByAdmin2(t *testing.T) {
+	a := geozip.Entry{geozip.PlaceName: "a", geozip.AdminCode2: "00"}
+	b := geozip.Entry{geozip.PlaceName: "b"}
+
+	groups := geozip.GroupByAdmin2([]geozip.Entry{a, b})
+	if got, want := groups["00"], []geozip.Entry{a}; !slices.Equal(got, want) {
+		t.Errorf("groups[00] = %v, want %v", got, want)
+	}
+	if got, want := groups[""], []geozip.Entry{b}; !slices.Equal(got, want) {
+		t.Errorf("groups[\"\"] = %v, want %v", got, want)
+	}
+}
+
+func TestFilterByAdmin1(t *testing.T) {
+	a := geozip.Entry{geozip.PlaceName: "a", geozip.AdminCode1: "RP"}
+	b := geozip.Entry{geozip.PlaceName: "b", geozip.AdminCode1: "BY"}
+	c := geozip.Entry{geozip.PlaceName: "c", geozip.AdminCode1: "RP"}
+	d := geozip.Entry{geozip.PlaceName: "d", geozip.AdminCode1: "rp"}
+	entries := []geozip.Entry{a, b, c, d}
+
+	if got, want := geozip.FilterByAdmin1(entries, "RP"), []geozip.Entry{a, c}; !slices.Equal(got, want) {
+		t.Errorf("FilterByAdmin1(RP) = %v, want %v", got, want)
+	}
+	if got := geozip.FilterByAdmin1(entries, "NW"); len(got) != 0 {
+		t.Errorf("FilterByAdmin1(NW) = %v, want none", got)
+	}
+}
+
+func TestFilterByAdmin2(t *testing.T) {
+	a := geozip.Entry{geozip.PlaceName: "a", geozip.AdminCode2: "00"}
+	b := geozip.Entry{geozip.PlaceName: "b", geozip.AdminCode2: "01"}
+
+	if got, want := geozip.FilterByAdmin2([]geozip.Entry{a, b}, "01"), []geozip.Entry{b}; !slices.Equal(got, want) {
+		t.Errorf("FilterByAdmin2(01) = %v, want %v", got, want)
+	}
+}
+
+func TestRequireFields(t *testing.T) {
+	a := geozip.Entry{geozip.PostalCode: "54668", geozip.PlaceName: "Ferschweiler"}
+	b := geozip.Entry{geozip.PostalCode: "56479"}
+	c := geozip.Entry{geozip.PlaceName: "Rehe"}
+	entries := []geozip.Entry{a, b, c}
+
+	if got, want := geozip.RequireFields(entries, geozip.PostalCode, geozip.PlaceName), []geozip.Entry{a}; !slices.Equal(got, want) {
+		t.Errorf("RequireFields(PostalCode, PlaceName) = %v, want %v", got, want)
+	}
+	if got, want := geozip.RequireFields(entries, geozip.PostalCode), []geozip.Entry{a, b}; !slices.Equal(got, want) {
+		t.Errorf("RequireFields(PostalCode) = %v, want %v", got, want)
+	}
+	if got := geozip.RequireFields(entries); !slices.Equal(got, entries) {
+		t.Errorf("RequireFields() = %v, want %v", got, entries)
+	}
+}
+
+func TestFilterByMinAccuracy(t *testing.T) {
+	a := geozip.Entry{geozip.PlaceName: "a", geozip.Accuracy: "1"}
+	b := geozip.Entry{geozip.PlaceName: "b", geozip.Accuracy: "4"}
+	c := geozip.Entry{geozip.PlaceName: "c"}
+	d := geozip.Entry{geozip.PlaceName: "d", geozip.Accuracy: "6"}
+	entries := []geozip.Entry{a, b, c, d}
+
+	if got, want := geozip.FilterByMinAccuracy(entries, 4, false), []geozip.Entry{b, d}; !slices.Equal(got, want) {
+		t.Errorf("FilterByMinAccuracy(4, false) = %v, want %v", got, want)
+	}
+	if got, want := geozip.FilterByMinAccuracy(entries, 4, true), []geozip.Entry{b, c, d}; !slices.Equal(got, want) {
+		t.Errorf("FilterByMinAccuracy(4, true) = %v, want %v", got, want)
+	}
+	if got, want := geozip.FilterByMinAccuracy(entries, 1, false), []geozip.Entry{a, b, d}; !slices.Equal(got, want) {
+		t.Errorf("FilterByMinAccuracy(1, false) = %v, want %v", got, want)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a := geozip.Entry{geozip.CountryCode: "DE", geozip.PostalCode: "54668"}
+	b := geozip.Entry{geozip.CountryCode: "AT", geozip.PostalCode: "1010"}
+	c := geozip.Entry{geozip.CountryCode: "CH", geozip.PostalCode: "8001"}
+
+	if got, want := geozip.Merge([]geozip.Entry{a}, nil, []geozip.Entry{b, c}), []geozip.Entry{a, b, c}; !slices.Equal(got, want) {
+		t.Errorf("Merge() = %v, want %v", got, want)
+	}
+	if got := geozip.Merge(); len(got) != 0 {
+		t.Errorf("Merge() = %v, want empty", got)
+	}
+}
+
+func TestMergeMap(t *testing.T) {
+	a := geozip.Entry{geozip.CountryCode: "DE", geozip.PostalCode: "54668"}
+	b := geozip.Entry{geozip.CountryCode: "AT", geozip.PostalCode: "1010"}
+	c := geozip.Entry{geozip.CountryCode: "CH", geozip.PostalCode: "8001"}
+
+	got := geozip.MergeMap(map[string][]geozip.Entry{"DE": {a}, "AT": {b}, "CH": {c}})
+	if want := []geozip.Entry{b, c, a}; !slices.Equal(got, want) {
+		t.Errorf("MergeMap() = %v, want %v", got, want)
+	}
+}
+
+func TestSplitByCountry(t *testing.T) {
+	a := geozip.Entry{geozip.CountryCode: "DE", geozip.PostalCode: "54668"}
+	b := geozip.Entry{geozip.CountryCode: "AT", geozip.PostalCode: "1010"}
+	c := geozip.Entry{geozip.CountryCode: "DE", geozip.PostalCode: "54636"}
+
+	split := geozip.SplitByCountry([]geozip.Entry{a, b, c})
+	if got, want := len(split), 2; got != want {
+		t.Errorf("len(split) = %v, want %v", got, want)
+	}
+	if got, want := split["DE"], []geozip.Entry{a, c}; !slices.Equal(got, want) {
+		t.Errorf("split[DE] = %v, want %v", got, want)
+	}
+	if got, want := split["AT"], []geozip.Entry{b}; !slices.Equal(got, want) {
+		t.Errorf("split[AT] = %v, want %v", got, want)
+	}
+	if got, want := geozip.MergeMap(split), []geozip.Entry{b, a, c}; !slices.Equal(got, want) {
+		t.Errorf("MergeMap(split) = %v, want %v", got, want)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	a := geozip.Entry{geozip.PostalCode: "54668", geozip.PlaceName: "Ferschweiler"}
+	b := a
+	if !geozip.Equal(a, b) {
+		t.Error("Equal(a, a) = false, want true")
+	}
+	b[geozip.Accuracy] = "4"
+	if geozip.Equal(a, b) {
+		t.Error("Equal(a, b) = true, want false")
+	}
+}
+
+func TestSortEntries(t *testing.T) {
+	a := geozip.Entry{geozip.CountryCode: "AT", geozip.PostalCode: "1010", geozip.PlaceName: "Wien"}
+	b := geozip.Entry{geozip.CountryCode: "DE", geozip.PostalCode: "54668", geozip.PlaceName: "Ferschweiler"}
+	c := geozip.Entry{geozip.CountryCode: "DE", geozip.PostalCode: "56479", geozip.PlaceName: "Neustadt (Westerwald)"}
+	d := geozip.Entry{geozip.CountryCode: "DE", geozip.PostalCode: "56479", geozip.PlaceName: "Rehe"}
+	e := geozip.Entry{geozip.CountryCode: "DE", geozip.PostalCode: "56479", geozip.PlaceName: "Rehe", geozip.AdminCode1: "RP"}
+
+	entries := []geozip.Entry{e, c, a, d, b}
+	geozip.SortEntries(entries)
+	if got, want := entries, []geozip.Entry{a, b, c, d, e}; !slices.Equal(got, want) {
+		t.Errorf("sorted = %v, want %v", got, want)
+	}
+	if geozip.Less(a, a) {
+		t.Error("Less(a, a) = true, want false")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := geozip.Entry{geozip.CountryCode: "DE", geozip.PostalCode: "54668", geozip.PlaceName: "Ferschweiler"}
+	b := geozip.Entry{geozip.CountryCode: "DE", geozip.PostalCode: "56479", geozip.PlaceName: "Rehe"}
+	c := geozip.Entry{geozip.CountryCode: "DE", geozip.PostalCode: "56479", geozip.PlaceName: "Neustadt (Westerwald)"}
+	bModified := b
+	bModified[geozip.Accuracy] = "4"
+
+	added, removed := geozip.Diff([]geozip.Entry{a, b, a}, []geozip.Entry{c, a, bModified})
+	if got, want := added, []geozip.Entry{c, bModified}; !slices.Equal(got, want) {
+		t.Errorf("added = %v, want %v", got, want)
+	}
+	if got, want := removed, []geozip.Entry{a, b}; !slices.Equal(got, want) {
+		t.Errorf("removed = %v, want %v", got, want)
+	}
+
+	added, removed = geozip.Diff([]geozip.Entry{a, b}, []geozip.Entry{b, a})
+	if len(added) != 0 || len(removed) != 0 {
+		t.Errorf("reordered: added, removed = %v, %v, want none", added, removed)
+	}
+}
+
+func TestProject(t *testing.T) {
+	e := geozip.Entry{"DE", "54668", "Ferschweiler", "Rheinland-Pfalz", "RP", "", "00", "Eifelkreis Bitburg-Prüm", "07232", "49.8667", "6.4", "4"}
+
+	rows := geozip.Project([]geozip.Entry{e}, geozip.PostalCode, geozip.Latitude, geozip.Longitude)
+	if got, want := rows[0], []string{"54668", "49.8667", "6.4"}; len(rows) != 1 || !slices.Equal(got, want) {
+		t.Errorf("Project() = %v, want [%v]", rows, want)
+	}
+
+	rows = geozip.Project([]geozip.Entry{e})
+	if got, want := rows[0], e[:]; len(rows) != 1 || !slices.Equal(got, want) {
+		t.Errorf("Project() without fields = %v, want [%v]", rows, want)
+	}
+}
@@ -0,0 +1,124 @@
+package geozip
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrCountryNotFound is returned, possibly wrapped, when GeoNames has no data for a country code,
+// i.e. the server responds with 404 Not Found. Use errors.Is to test for it.
+//
+// Note that unchanged data is not reported as an error. Instead, the fetch functions report modified=false.
+var ErrCountryNotFound = errors.New("country not found")
+
+// ErrTruncatedDownload is returned, possibly wrapped, when a response body is shorter than announced by its
+// Content-Length header, e.g. because the connection dropped mid-download. Such downloads are retried according
+// to the client's RetryPolicy. Use errors.Is to distinguish them from corrupt archives.
+var ErrTruncatedDownload = errors.New("truncated download")
+
+// ErrTooManyFields is returned, possibly wrapped, when a row of postal code data has more than the 12 fields
+// of an Entry, e.g. because GeoNames added columns. Such rows are rejected rather than silently truncated.
+var ErrTooManyFields = errors.New("too many fields")
+
+// ErrResponseTooLarge is returned, possibly wrapped, when a response body exceeds the MaxBytes of a Client.
+// Such responses are not retried.
+var ErrResponseTooLarge = errors.New("response too large")
+
+// ErrInvalidUTF8 is returned, possibly wrapped, when postal code data is not valid UTF-8,
+// e.g. because it was converted to Latin-1. GeoNames data is always encoded as UTF-8. Use errors.Is to test for it.
+var ErrInvalidUTF8 = errors.New("invalid UTF-8")
+
+// ErrInvalidArchive is returned, possibly wrapped, when a downloaded or local archive cannot be read, e.g. because
+// it is not a zip archive or is corrupt. Unlike network errors, such errors are not resolved by retrying the fetch,
+// unless the data on the server changes. Use errors.Is to test for it.
+var ErrInvalidArchive = errors.New("invalid archive")
+
+// ErrMemberNotFound is returned, possibly wrapped, when an archive lacks the requested member,
+// e.g. for FetchMember, or has no member matching the MemberPattern of a Client. Use errors.Is to test for it.
+var ErrMemberNotFound = errors.New("member not found")
+
+// ErrChecksumMismatch is returned, possibly wrapped, by VerifyChecksum when data does not match its checksum,
+// e.g. because it was corrupted on disk. Use errors.Is to test for it.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// statusError reports a response with an unexpected HTTP status code.
+type statusError struct {
+	code   int
+	status string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("status = %s, want 200", e.status)
+}
+
+// ParseError is returned, possibly wrapped, when postal code data is malformed.
+// It adds the offending raw line to the underlying error, which is usually a *csv.ParseError.
+type ParseError struct {
+	// Line is the 1-based number of the offending line.
+	Line int
+	// Snippet is the offending raw line, truncated if it is long.
+	Snippet string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%v (line %d: %q)", e.Err, e.Line, e.Snippet)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// maxSnippet is the maximum length of ParseError.Snippet in bytes.
+const maxSnippet = 200
+
+// withLineContext turns a *csv.ParseError into a *ParseError by looking up the offending line in the input
+// returned by open. Other errors, and errors for which the line cannot be looked up, are returned unchanged.
+func withLineContext(err error, open func() (io.ReadCloser, error)) error {
+	var perr *csv.ParseError
+	if !errors.As(err, &perr) {
+		return err
+	}
+	rc, openErr := open()
+	if openErr != nil {
+		return err
+	}
+	defer rc.Close()
+
+	snippet, ok := lineSnippet(rc, perr.Line)
+	if !ok {
+		return err
+	}
+	return &ParseError{Line: perr.Line, Snippet: snippet, Err: err}
+}
+
+// lineSnippet returns the 1-based line of r, truncated to maxSnippet bytes at a rune boundary.
+func lineSnippet(r io.Reader, line int) (string, bool) {
+	br := bufio.NewReader(r)
+	for i := 1; ; i++ {
+		text, err := br.ReadString('\n')
+		if i == line && (err == nil || len(text) > 0) {
+			text = strings.TrimRight(text, "\r\n")
+			if i == 1 {
+				text = strings.TrimPrefix(text, "\ufeff")
+			}
+			if len(text) > maxSnippet {
+				n := maxSnippet
+				for n > 0 && !utf8.RuneStart(text[n]) {
+					n--
+				}
+				text = text[:n]
+			}
+			return text, true
+		}
+		if err != nil {
+			return "", false
+		}
+	}
+}
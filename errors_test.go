@@ -0,0 +1,211 @@
+package geozip_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/ngrash/geozip"
+)
+
+func TestFetchCountry_ErrCountryNotFound(t *testing.T) {
+	client := &geozip.Client{
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusNotFound, Status: "404 Not Found"}, nil
+			}),
+		},
+	}
+
+	_, _, _, err := client.FetchCountry("AQ", "")
+	if !errors.Is(err, geozip.ErrCountryNotFound) {
+		t.Errorf("err = %v, want %v", err, geozip.ErrCountryNotFound)
+	}
+	if got, want := err.Error(), "country not found: status = 404 Not Found, want 200"; got != want {
+		t.Errorf("err = %q, want %q", got, want)
+	}
+}
+
+func TestFetchCountry_OtherStatus(t *testing.T) {
+	client := &geozip.Client{
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusForbidden, Status: "403 Forbidden"}, nil
+			}),
+		},
+	}
+
+	_, _, _, err := client.FetchCountry("DE", "")
+	if err == nil {
+		t.Fatal("err = nil, want error")
+	}
+	if errors.Is(err, geozip.ErrCountryNotFound) {
+		t.Errorf("err = %v, should not be %v", err, geozip.ErrCountryNotFound)
+	}
+}
+
+func TestParseStrict_ParseError(t *testing.T) {
+	const data = "DE\t54668\tFerschweiler\tRheinland-Pfalz\tRP\t\t00\tEifelkreis Bitburg-Prüm\t07232\t49.8667\t6.4\t4\n" +
+		"DE\t56479\tNeustadt \"Westerwald\"\tRheinland-Pfalz\tRP\t\t00\tWesterwaldkreis\t07143\t50.6333\t8.0333\t\n"
+
+	_, err := geozip.ParseStrict([]byte(data))
+	var perr *geozip.ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("err = %v, want *geozip.ParseError", err)
+	}
+	if got, want := perr.Line, 2; got != want {
+		t.Errorf("Line = %v, want %v", got, want)
+	}
+	if got, want := perr.Snippet, "DE\t56479\tNeustadt \"Westerwald\"\tRheinland-Pfalz\tRP\t\t00\tWesterwaldkreis\t07143\t50.6333\t8.0333\t"; got != want {
+		t.Errorf("Snippet = %q, want %q", got, want)
+	}
+	var cerr *csv.ParseError
+	if !errors.As(err, &cerr) {
+		t.Errorf("err = %v, want it to wrap *csv.ParseError", err)
+	}
+}
+
+func TestParseError_LongLine(t *testing.T) {
+	data := "a" + strings.Repeat("ü", 150) + "\"\n"
+
+	_, err := geozip.ParseWithOptions([]byte(data), geozip.ParseOptions{})
+	var perr *geozip.ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("err = %v, want *geozip.ParseError", err)
+	}
+	if got, want := perr.Snippet, "a"+strings.Repeat("ü", 99); got != want {
+		t.Errorf("Snippet = %q, want %q", got, want)
+	}
+}
+
+func TestParseReader_ParseError(t *testing.T) {
+	data := zipArchive(t, map[string]string{
+		"DE.txt": "DE\t54668\tFerschweiler\nDE\t56479\tNeu\"stadt\n",
+	})
+
+	_, err := geozip.ParseReader(bytes.NewReader(data), "DE")
+	var perr *geozip.ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("err = %v, want *geozip.ParseError", err)
+	}
+	if got, want := perr.Line, 2; got != want {
+		t.Errorf("Line = %v, want %v", got, want)
+	}
+	if got, want := perr.Snippet, "DE\t56479\tNeu\"stadt"; got != want {
+		t.Errorf("Snippet = %q, want %q", got, want)
+	}
+}
+
+func TestFetchCountry_ErrTruncatedDownload(t *testing.T) {
+	data, err := os.ReadFile("test_data/DE.zip")
+	if err != nil {
+		t.Fatal("read test data", err)
+	}
+	calls := 0
+	client := &geozip.Client{
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				calls++
+				return &http.Response{
+					StatusCode:    http.StatusOK,
+					Body:          io.NopCloser(bytes.NewReader(data[:len(data)/2])),
+					ContentLength: int64(len(data)),
+				}, nil
+			}),
+		},
+		RetryPolicy: geozip.RetryPolicy{MaxRetries: 1, BaseDelay: time.Millisecond},
+	}
+
+	_, _, _, err = client.FetchCountry("DE", "")
+	if !errors.Is(err, geozip.ErrTruncatedDownload) {
+		t.Errorf("err = %v, want %v", err, geozip.ErrTruncatedDownload)
+	}
+	if got, want := calls, 2; got != want {
+		t.Errorf("transport called %d times, want %d", got, want)
+	}
+}
+
+func TestFetchCountry_TruncatedBodyUnexpectedEOF(t *testing.T) {
+	client := &geozip.Client{
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode:    http.StatusOK,
+					Body:          io.NopCloser(iotest.ErrReader(io.ErrUnexpectedEOF)),
+					ContentLength: 100,
+				}, nil
+			}),
+		},
+	}
+
+	if _, _, _, err := client.FetchCountry("DE", ""); !errors.Is(err, geozip.ErrTruncatedDownload) {
+		t.Errorf("err = %v, want %v", err, geozip.ErrTruncatedDownload)
+	}
+}
+
+func TestErrInvalidArchive(t *testing.T) {
+	var stored bytes.Buffer
+	w := zip.NewWriter(&stored)
+	f, err := w.CreateHeader(&zip.FileHeader{Name: "DE.txt", Method: zip.Store})
+	if err != nil {
+		t.Fatal("create zip member", err)
+	}
+	if _, err := io.WriteString(f, "DE\t54668\tFerschweiler\n"); err != nil {
+		t.Fatal("write zip member", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal("close zip writer", err)
+	}
+	// Corrupt the stored content, so that its checksum no longer matches.
+	corrupt := bytes.Replace(stored.Bytes(), []byte("Ferschweiler"), []byte("Gerschweiler"), 1)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"not an archive", []byte("<html>Service Unavailable</html>")},
+		{"checksum mismatch", corrupt},
+		{"truncated gzip", []byte{0x1f, 0x8b, 0x08}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := geozip.ParseReader(bytes.NewReader(tt.data), "DE")
+			if !errors.Is(err, geozip.ErrInvalidArchive) {
+				t.Errorf("err = %v, want %v", err, geozip.ErrInvalidArchive)
+			}
+			if errors.Is(err, geozip.ErrMemberNotFound) {
+				t.Errorf("err = %v, want not %v", err, geozip.ErrMemberNotFound)
+			}
+		})
+	}
+}
+
+func TestErrMemberNotFound(t *testing.T) {
+	data := zipArchive(t, map[string]string{"DE.txt": "DE\t54668\tFerschweiler\n"})
+
+	client := &geozip.Client{HTTPClient: &http.Client{Transport: serveBytes(data, `"etag"`)}}
+	_, _, _, err := client.FetchMember("DE", "", "readme.txt")
+	if !errors.Is(err, geozip.ErrMemberNotFound) {
+		t.Errorf("FetchMember: err = %v, want %v", err, geozip.ErrMemberNotFound)
+	}
+	if !strings.Contains(err.Error(), "zipfile missing readme.txt") {
+		t.Errorf("FetchMember: err = %v, want message naming the member", err)
+	}
+
+	client.MemberPattern = "AT*.txt"
+	_, _, _, err = client.FetchCountry("DE", "")
+	if !errors.Is(err, geozip.ErrMemberNotFound) {
+		t.Errorf("MemberPattern: err = %v, want %v", err, geozip.ErrMemberNotFound)
+	}
+	if errors.Is(err, geozip.ErrInvalidArchive) {
+		t.Errorf("MemberPattern: err = %v, want not %v", err, geozip.ErrInvalidArchive)
+	}
+}
@@ -0,0 +1,16 @@
+package geozip
+
+import "time"
+
+// NewRateLimiterClock returns a limiter like NewRateLimiter that reads the current time from now.
+func NewRateLimiterClock(requestsPerSecond float64, burst int, now func() time.Time) Limiter {
+	l := NewRateLimiter(requestsPerSecond, burst).(*rateLimiter)
+	l.now = now
+	l.last = now()
+	return l
+}
+
+// Reserve exposes rateLimiter.reserve.
+func Reserve(l Limiter) time.Duration {
+	return l.(*rateLimiter).reserve()
+}
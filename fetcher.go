@@ -0,0 +1,36 @@
+package geozip
+
+// Fetcher fetches postal code entries for a specific country code, with the ETag handling described
+// for the package-level FetchCountry. Both *Client and *CachedClient implement Fetcher.
+//
+// Code that fetches postal code data can accept a Fetcher instead of calling FetchCountry directly,
+// so that tests can inject a fake rather than swapping the transport of HTTPClient:
+//
+//	func refresh(f geozip.Fetcher, etag string) error {
+//	    entries, modified, newEtag, err := f.FetchCountry("DE", etag)
+//	    ...
+//	}
+//
+//	// In production:
+//	refresh(geozip.New(), etag)
+//
+//	// In tests:
+//	refresh(geozip.FetcherFunc(func(cc, etag string) ([]geozip.Entry, bool, string, error) {
+//	    return []geozip.Entry{{"DE", "54668", "Ferschweiler"}}, true, `"v2"`, nil
+//	}), `"v1"`)
+type Fetcher interface {
+	FetchCountry(cc, etag string) (entries []Entry, modified bool, newEtag string, err error)
+}
+
+// FetcherFunc is an adapter to use an ordinary function as a Fetcher, e.g. as a fake in tests.
+type FetcherFunc func(cc, etag string) (entries []Entry, modified bool, newEtag string, err error)
+
+// FetchCountry calls f(cc, etag).
+func (f FetcherFunc) FetchCountry(cc, etag string) (entries []Entry, modified bool, newEtag string, err error) {
+	return f(cc, etag)
+}
+
+var (
+	_ Fetcher = (*Client)(nil)
+	_ Fetcher = (*CachedClient)(nil)
+)
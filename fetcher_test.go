@@ -0,0 +1,53 @@
+package geozip_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ngrash/geozip"
+)
+
+// placeNames fetches the entries for cc with f and returns their place names, as an example consumer of Fetcher.
+func placeNames(f geozip.Fetcher, cc string) ([]string, error) {
+	entries, _, _, err := f.FetchCountry(cc, "")
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e[geozip.PlaceName]
+	}
+	return names, nil
+}
+
+func TestFetcherFunc(t *testing.T) {
+	var requested string
+	fake := geozip.FetcherFunc(func(cc, etag string) ([]geozip.Entry, bool, string, error) {
+		requested = cc
+		return []geozip.Entry{{"DE", "54668", "Ferschweiler"}}, true, `"etag"`, nil
+	})
+
+	names, err := placeNames(fake, "DE")
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if len(names) != 1 || names[0] != "Ferschweiler" {
+		t.Errorf("names = %v, want [Ferschweiler]", names)
+	}
+	if requested != "DE" {
+		t.Errorf("requested %q, want %q", requested, "DE")
+	}
+}
+
+func TestClient_Fetcher(t *testing.T) {
+	data := zipArchive(t, map[string]string{"DE.txt": "DE\t54668\tFerschweiler\n"})
+	client := &geozip.Client{HTTPClient: &http.Client{Transport: serveBytes(data, `"etag"`)}}
+
+	names, err := placeNames(client, "DE")
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if len(names) != 1 || names[0] != "Ferschweiler" {
+		t.Errorf("names = %v, want [Ferschweiler]", names)
+	}
+}
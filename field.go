@@ -0,0 +1,75 @@
+package geozip
+
+import "strconv"
+
+var fieldNames = [numFields]string{
+	CountryCode: "CountryCode",
+	PostalCode:  "PostalCode",
+	PlaceName:   "PlaceName",
+	AdminName1:  "AdminName1",
+	AdminCode1:  "AdminCode1",
+	AdminName2:  "AdminName2",
+	AdminCode2:  "AdminCode2",
+	AdminName3:  "AdminName3",
+	AdminCode3:  "AdminCode3",
+	Latitude:    "Latitude",
+	Longitude:   "Longitude",
+	Accuracy:    "Accuracy",
+}
+
+// String returns the name of the field, e.g. "PostalCode".
+// Invalid fields are rendered as "Field(n)".
+func (f Field) String() string {
+	if !f.valid() {
+		return "Field(" + strconv.Itoa(int(f)) + ")"
+	}
+	return fieldNames[f]
+}
+
+// ParseField returns the field with the given name, as returned by Field.String.
+// It reports false if there is no such field.
+func ParseField(name string) (Field, bool) {
+	for i, n := range fieldNames {
+		if n == name {
+			return Field(i), true
+		}
+	}
+	return 0, false
+}
+
+// valid reports whether f is one of the field constants.
+func (f Field) valid() bool {
+	return f >= 0 && int(f) < numFields
+}
+
+// Get returns the value of field f, or the empty string if f is not a valid field.
+// Unlike indexing e directly, it never panics.
+func (e Entry) Get(f Field) string {
+	if !f.valid() {
+		return ""
+	}
+	return e[f]
+}
+
+// Has reports whether field f is non-empty. It returns false if f is not a valid field.
+func (e Entry) Has(f Field) bool {
+	return e.Get(f) != ""
+}
+
+// NewEntry returns an entry with the given fields set and all others empty, e.g. for test fixtures:
+//
+//	e := geozip.NewEntry(map[geozip.Field]string{
+//	    geozip.PostalCode: "54668",
+//	    geozip.PlaceName:  "Ferschweiler",
+//	})
+//
+// Invalid fields are ignored.
+func NewEntry(fields map[Field]string) Entry {
+	var e Entry
+	for f, v := range fields {
+		if f.valid() {
+			e[f] = v
+		}
+	}
+	return e
+}
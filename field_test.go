@@ -0,0 +1,98 @@
+package geozip_test
+
+import (
+	"testing"
+
+	"github.com/ngrash/geozip"
+)
+
+func TestField_String(t *testing.T) {
+	tests := []struct {
+		field geozip.Field
+		name  string
+	}{
+		{geozip.CountryCode, "CountryCode"},
+		{geozip.PostalCode, "PostalCode"},
+		{geozip.PlaceName, "PlaceName"},
+		{geozip.AdminName1, "AdminName1"},
+		{geozip.AdminCode1, "AdminCode1"},
+		{geozip.AdminName2, "AdminName2"},
+		{geozip.AdminCode2, "AdminCode2"},
+		{geozip.AdminName3, "AdminName3"},
+		{geozip.AdminCode3, "AdminCode3"},
+		{geozip.Latitude, "Latitude"},
+		{geozip.Longitude, "Longitude"},
+		{geozip.Accuracy, "Accuracy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, want := tt.field.String(), tt.name; got != want {
+				t.Errorf("String() = %v, want %v", got, want)
+			}
+			f, ok := geozip.ParseField(tt.name)
+			if !ok {
+				t.Fatalf("ParseField(%q) ok = false, want true", tt.name)
+			}
+			if got, want := f, tt.field; got != want {
+				t.Errorf("ParseField(%q) = %v, want %v", tt.name, got, want)
+			}
+		})
+	}
+}
+
+func TestField_String_Invalid(t *testing.T) {
+	if got, want := geozip.Field(12).String(), "Field(12)"; got != want {
+		t.Errorf("String() = %v, want %v", got, want)
+	}
+	if got, want := geozip.Field(-1).String(), "Field(-1)"; got != want {
+		t.Errorf("String() = %v, want %v", got, want)
+	}
+}
+
+func TestParseField_Unknown(t *testing.T) {
+	if _, ok := geozip.ParseField("ZipCode"); ok {
+		t.Error("ok = true, want false")
+	}
+}
+
+func TestEntry_Get(t *testing.T) {
+	e := geozip.Entry{geozip.PostalCode: "54668"}
+
+	if got, want := e.Get(geozip.PostalCode), "54668"; got != want {
+		t.Errorf("Get(PostalCode) = %q, want %q", got, want)
+	}
+	if got, want := e.Get(geozip.PlaceName), ""; got != want {
+		t.Errorf("Get(PlaceName) = %q, want %q", got, want)
+	}
+	for _, f := range []geozip.Field{-1, 12, 100} {
+		if got, want := e.Get(f), ""; got != want {
+			t.Errorf("Get(%v) = %q, want %q", f, got, want)
+		}
+	}
+}
+
+func TestEntry_Has(t *testing.T) {
+	e := geozip.Entry{geozip.PostalCode: "54668"}
+
+	if !e.Has(geozip.PostalCode) {
+		t.Error("Has(PostalCode) = false, want true")
+	}
+	if e.Has(geozip.PlaceName) {
+		t.Error("Has(PlaceName) = true, want false")
+	}
+	if e.Has(12) {
+		t.Error("Has(Field(12)) = true, want false")
+	}
+}
+
+func TestNewEntry(t *testing.T) {
+	e := geozip.NewEntry(map[geozip.Field]string{
+		geozip.PostalCode: "54668",
+		geozip.PlaceName:  "Ferschweiler",
+		geozip.Field(42):  "ignored",
+	})
+	want := geozip.Entry{geozip.PostalCode: "54668", geozip.PlaceName: "Ferschweiler"}
+	if e != want {
+		t.Errorf("NewEntry() = %v, want %v", e, want)
+	}
+}
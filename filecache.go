@@ -0,0 +1,173 @@
+package geozip
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// FileCacheTransport is an http.RoundTripper that caches responses on disk, keyed by URL and ETag,
+// so that repeated runs of a program or test suite do not download unchanged archives again:
+//
+//	client := &geozip.Client{
+//	    HTTPClient: &http.Client{Transport: &geozip.FileCacheTransport{Dir: "testdata/cache"}},
+//	}
+//
+// For a cached URL, requests are made conditional on the cached ETag. If the server reports the data as unchanged,
+// the cached response is replayed, or 304 Not Modified is returned if the request itself carries the cached ETag
+// in its If-None-Match header, just as the server would. If the server cannot be reached, the cached response is
+// replayed as well. Only successful GET responses with an ETag and without a Content-Encoding are cached;
+// other responses pass through.
+type FileCacheTransport struct {
+	// Dir is the directory that holds the cache. It is created if it does not exist.
+	Dir string
+	// Transport makes the actual requests. If nil, http.DefaultTransport is used.
+	Transport http.RoundTripper
+	// Offline, if set, replays cached responses without contacting the server, which makes tests hermetic.
+	// Requests for URLs that are not cached are still passed through.
+	Offline bool
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *FileCacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.transport().RoundTrip(req)
+	}
+
+	path := t.path(req.URL.String())
+	etag, cached := t.cachedETag(path)
+	if cached && t.Offline {
+		return t.replay(req, path, etag)
+	}
+
+	upstream := req
+	if cached {
+		upstream = req.Clone(req.Context())
+		upstream.Header.Set("If-None-Match", etag)
+	}
+	resp, err := t.transport().RoundTrip(upstream)
+	if err != nil {
+		if cached {
+			return t.replay(req, path, etag)
+		}
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached:
+		if resp.Body != nil {
+			resp.Body.Close()
+		}
+		return t.replay(req, path, etag)
+	case resp.StatusCode == http.StatusOK && resp.Header.Get("Etag") != "" && resp.Header.Get("Content-Encoding") == "":
+		defer resp.Body.Close()
+		if err := t.store(path, resp); err != nil {
+			return nil, fmt.Errorf("cache response: %w", err)
+		}
+		return t.replay(req, path, resp.Header.Get("Etag"))
+	default:
+		return resp, nil
+	}
+}
+
+func (t *FileCacheTransport) transport() http.RoundTripper {
+	if t.Transport != nil {
+		return t.Transport
+	}
+	return http.DefaultTransport
+}
+
+// path returns the path of the cache files for url, without extension.
+func (t *FileCacheTransport) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(t.Dir, hex.EncodeToString(sum[:]))
+}
+
+// cachedETag returns the ETag of the response cached at path, if any.
+func (t *FileCacheTransport) cachedETag(path string) (string, bool) {
+	etag, err := os.ReadFile(path + ".etag")
+	if err != nil {
+		return "", false
+	}
+	return string(etag), true
+}
+
+// store writes the body and ETag of resp to the cache files at path.
+// The ETag is written last, so that it only refers to complete bodies.
+func (t *FileCacheTransport) store(path string, resp *http.Response) error {
+	if err := os.MkdirAll(t.Dir, 0o755); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path+".body", resp.Body); err != nil {
+		return err
+	}
+	return writeFileAtomic(path+".etag", strings.NewReader(resp.Header.Get("Etag")))
+}
+
+// replay returns the response cached at path with the given ETag as the response to req.
+func (t *FileCacheTransport) replay(req *http.Request, path, etag string) (*http.Response, error) {
+	header := http.Header{"Etag": []string{etag}}
+	// The client normalizes the quoting of ETags, so the request may carry the cached ETag in a different form.
+	if inm := req.Header.Get("If-None-Match"); inm != "" && normalizeETag(inm) == normalizeETag(etag) {
+		return &http.Response{
+			Status:     "304 Not Modified",
+			StatusCode: http.StatusNotModified,
+			Proto:      "HTTP/1.1", ProtoMajor: 1, ProtoMinor: 1,
+			Header:  header,
+			Body:    http.NoBody,
+			Request: req,
+		}, nil
+	}
+
+	f, err := os.Open(path + ".body")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("cached body for %s missing: %w", req.URL, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	header.Set("Content-Length", strconv.FormatInt(info.Size(), 10))
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Proto:      "HTTP/1.1", ProtoMajor: 1, ProtoMinor: 1,
+		Header:        header,
+		Body:          f,
+		ContentLength: info.Size(),
+		Request:       req,
+	}, nil
+}
+
+// writeFileAtomic writes the content of r to name via a temporary file, so that name is never partially written.
+func writeFileAtomic(name string, r io.Reader) (err error) {
+	f, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(f.Name())
+		}
+	}()
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
@@ -0,0 +1,116 @@
+package geozip_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/ngrash/geozip"
+)
+
+func TestFileCacheTransport(t *testing.T) {
+	data, err := os.ReadFile("test_data/DE.zip")
+	if err != nil {
+		t.Fatal("read test data", err)
+	}
+	var downloads int
+	dir := t.TempDir()
+	newClient := func(upstream http.RoundTripper) *geozip.Client {
+		return &geozip.Client{
+			HTTPClient: &http.Client{Transport: &geozip.FileCacheTransport{Dir: dir, Transport: upstream}},
+		}
+	}
+
+	// The first run downloads and caches the archive.
+	entries, modified, etag, err := newClient(etagServer(t, data, "etag", &downloads)).FetchCountry("DE", "")
+	if err != nil {
+		t.Fatalf("first run: err = %v, want nil", err)
+	}
+	if got, want := len(entries), 16477; got != want || !modified || etag != `"etag"` {
+		t.Errorf("first run: len(entries), modified, etag = %v, %v, %v, want %v, true, \"etag\"", got, modified, etag, want)
+	}
+
+	// A later run without an ETag gets the cached archive after revalidation.
+	entries, modified, _, err = newClient(etagServer(t, data, "etag", &downloads)).FetchCountry("DE", "")
+	if err != nil {
+		t.Fatalf("second run: err = %v, want nil", err)
+	}
+	if got, want := len(entries), 16477; got != want || !modified {
+		t.Errorf("second run: len(entries), modified = %v, %v, want %v, true", got, modified, want)
+	}
+
+	// A request with the cached ETag is not modified.
+	_, modified, _, err = newClient(etagServer(t, data, "etag", &downloads)).FetchCountry("DE", "etag")
+	if err != nil || modified {
+		t.Errorf("third run: modified, err = %v, %v, want false, nil", modified, err)
+	}
+
+	if got, want := downloads, 1; got != want {
+		t.Errorf("%d downloads, want %d", got, want)
+	}
+
+	// The cached archive is replayed if the server cannot be reached.
+	unreachable := RoundTripperFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})
+	entries, _, _, err = newClient(unreachable).FetchCountry("DE", "")
+	if err != nil {
+		t.Fatalf("unreachable: err = %v, want nil", err)
+	}
+	if got, want := len(entries), 16477; got != want {
+		t.Errorf("unreachable: len(entries) = %v, want %v", got, want)
+	}
+}
+
+func TestFileCacheTransport_Offline(t *testing.T) {
+	data := zipArchive(t, map[string]string{"DE.txt": "DE\t54668\tFerschweiler\n"})
+	dir := t.TempDir()
+	requests := 0
+	upstream := RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		requests++
+		return serveBytes(data, "etag")(r)
+	})
+	client := &geozip.Client{
+		HTTPClient: &http.Client{Transport: &geozip.FileCacheTransport{Dir: dir, Transport: upstream, Offline: true}},
+	}
+
+	for i := 0; i < 2; i++ {
+		entries, _, _, err := client.FetchCountry("DE", "")
+		if err != nil {
+			t.Fatalf("run %d: err = %v, want nil", i, err)
+		}
+		if got, want := len(entries), 1; got != want {
+			t.Errorf("run %d: len(entries) = %v, want %v", i, got, want)
+		}
+	}
+	if got, want := requests, 1; got != want {
+		t.Errorf("%d requests, want %d", got, want)
+	}
+}
+
+func TestFileCacheTransport_UnquotedETag(t *testing.T) {
+	data := zipArchive(t, map[string]string{"DE.txt": "DE\t54668\tFerschweiler\n"})
+	for _, offline := range []bool{false, true} {
+		var downloads int
+		client := &geozip.Client{
+			HTTPClient: &http.Client{Transport: &geozip.FileCacheTransport{
+				Dir:       t.TempDir(),
+				Transport: etagServer(t, data, "abc", &downloads),
+				Offline:   offline,
+			}},
+		}
+
+		_, modified, etag, err := client.FetchCountry("DE", "")
+		if err != nil || !modified || etag != `"abc"` {
+			t.Fatalf("offline %v: first fetch: modified, etag, err = %v, %s, %v, want true, \"abc\", nil", offline, modified, etag, err)
+		}
+		entries, modified, _, err := client.FetchCountry("DE", etag)
+		if err != nil || modified || entries != nil {
+			t.Errorf("offline %v: revalidation: entries, modified, err = %v, %v, %v, want nil, false, nil", offline, entries, modified, err)
+		}
+		if got, want := downloads, 1; got != want {
+			t.Errorf("offline %v: %d downloads, want %d", offline, got, want)
+		}
+	}
+}
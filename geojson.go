@@ -0,0 +1,75 @@
+package geozip
+
+import "encoding/json"
+
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string            `json:"type"`
+	Geometry   *geoJSONPoint     `json:"geometry"`
+	Properties geoJSONProperties `json:"properties"`
+}
+
+type geoJSONPoint struct {
+	Type string `json:"type"`
+	// Coordinates are in longitude, latitude order as required by RFC 7946.
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+type geoJSONProperties struct {
+	CountryCode string `json:"countryCode"`
+	PostalCode  string `json:"postalCode"`
+	PlaceName   string `json:"placeName"`
+	AdminName1  string `json:"adminName1,omitempty"`
+	AdminCode1  string `json:"adminCode1,omitempty"`
+	AdminName2  string `json:"adminName2,omitempty"`
+	AdminCode2  string `json:"adminCode2,omitempty"`
+	AdminName3  string `json:"adminName3,omitempty"`
+	AdminCode3  string `json:"adminCode3,omitempty"`
+}
+
+// ToGeoJSON encodes entries as a GeoJSON FeatureCollection (RFC 7946), e.g. for display on a web map.
+// Each entry becomes a Point feature carrying the country code, postal code, place name and non-empty admin
+// fields as properties. Entries without valid coordinates are omitted.
+func ToGeoJSON(entries []Entry) ([]byte, error) {
+	return toGeoJSON(entries, false)
+}
+
+// ToGeoJSONWithNullGeometry is like ToGeoJSON, but includes entries without valid coordinates
+// as features with a null geometry.
+func ToGeoJSONWithNullGeometry(entries []Entry) ([]byte, error) {
+	return toGeoJSON(entries, true)
+}
+
+func toGeoJSON(entries []Entry, includeNull bool) ([]byte, error) {
+	fc := geoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: make([]geoJSONFeature, 0, len(entries)),
+	}
+	for _, e := range entries {
+		f := geoJSONFeature{
+			Type: "Feature",
+			Properties: geoJSONProperties{
+				CountryCode: e[CountryCode],
+				PostalCode:  e[PostalCode],
+				PlaceName:   e[PlaceName],
+				AdminName1:  e[AdminName1],
+				AdminCode1:  e[AdminCode1],
+				AdminName2:  e[AdminName2],
+				AdminCode2:  e[AdminCode2],
+				AdminName3:  e[AdminName3],
+				AdminCode3:  e[AdminCode3],
+			},
+		}
+		if lat, lon, err := coordinates(e); err == nil {
+			f.Geometry = &geoJSONPoint{Type: "Point", Coordinates: [2]float64{lon, lat}}
+		} else if !includeNull {
+			continue
+		}
+		fc.Features = append(fc.Features, f)
+	}
+	return json.Marshal(fc)
+}
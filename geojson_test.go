@@ -0,0 +1,50 @@
+package geozip_test
+
+import (
+	"testing"
+
+	"github.com/ngrash/geozip"
+)
+
+var geoJSONEntries = []geozip.Entry{
+	{"DE", "54668", "Ferschweiler", "Rheinland-Pfalz", "RP", "", "00", "Eifelkreis Bitburg-Prüm", "07232", "49.8667", "6.4", "4"},
+	{"DE", "99999", "Nowhere"},
+}
+
+func TestToGeoJSON(t *testing.T) {
+	data, err := geozip.ToGeoJSON(geoJSONEntries)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	const want = `{"type":"FeatureCollection","features":[` +
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[6.4,49.8667]},` +
+		`"properties":{"countryCode":"DE","postalCode":"54668","placeName":"Ferschweiler",` +
+		`"adminName1":"Rheinland-Pfalz","adminCode1":"RP","adminCode2":"00",` +
+		`"adminName3":"Eifelkreis Bitburg-Prüm","adminCode3":"07232"}}]}`
+	if got := string(data); got != want {
+		t.Errorf("ToGeoJSON() = %s, want %s", got, want)
+	}
+}
+
+func TestToGeoJSONWithNullGeometry(t *testing.T) {
+	data, err := geozip.ToGeoJSONWithNullGeometry(geoJSONEntries[1:])
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	const want = `{"type":"FeatureCollection","features":[` +
+		`{"type":"Feature","geometry":null,` +
+		`"properties":{"countryCode":"DE","postalCode":"99999","placeName":"Nowhere"}}]}`
+	if got := string(data); got != want {
+		t.Errorf("ToGeoJSONWithNullGeometry() = %s, want %s", got, want)
+	}
+}
+
+func TestToGeoJSON_Empty(t *testing.T) {
+	data, err := geozip.ToGeoJSON(nil)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if got, want := string(data), `{"type":"FeatureCollection","features":[]}`; got != want {
+		t.Errorf("ToGeoJSON() = %s, want %s", got, want)
+	}
+}
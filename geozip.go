@@ -3,23 +3,27 @@
 package geozip
 
 import (
-	"archive/zip"
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/csv"
 	"errors"
 	"fmt"
 	"io"
-	"net/http"
+	"os"
+	"path"
+	"sort"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
-// HTTPClient is a global http.Client instance used for making HTTP requests.
-// This can be replaced or configured as needed to change the default HTTP behavior.
-var HTTPClient http.Client
-
 // Entry represents a single postal code entry. It is an array of 12 strings, each representing a specific field of data.
 type Entry [12]string
 
+// numFields is the number of fields in a postal code entry.
+const numFields = len(Entry{})
+
 // Field represents a specific field in a postal code entry.
 type Field int
 
@@ -59,10 +63,16 @@ const (
 //	etag: An ETag value from a previous request to this function.
 //
 // If the data for the given country code has not changed since the last request with the provided ETag,
-// the function returns with 'modified' set to false, and no new data is fetched.
+// the function returns with 'modified' set to false and nil entries, and no new data is fetched.
 //
 // If the data has changed, or if this is the first request (indicated by an empty etag),
 // the function fetches the updated data, sets 'modified' to true, and returns the new data along with the new ETag.
+// If the fetched data has no entries, they are returned as a non-nil empty slice, so that "no data" can be told
+// apart from "not fetched".
+//
+// The archive is expected in zip format, but gzip-compressed data, either a tar archive or a single
+// plain text file, is detected by its magic bytes and decompressed accordingly, in case a mirror serves
+// the data in a different format.
 //
 // Example usage:
 //
@@ -77,26 +87,176 @@ const (
 //
 // See https://download.geonames.org/export/zip/ for a list of available countries.
 func FetchCountry(cc, etag string) (entries []Entry, modified bool, newEtag string, err error) {
+	return defaultClient.FetchCountry(cc, etag)
+}
+
+// ParseReader parses postal code entries from a GeoNames zip archive read from r.
+// It is useful for parsing data that has already been downloaded, without any network access.
+// If r supports random access, like an *os.File or *bytes.Reader, and has not been read from yet, the archive is
+// read in place rather than being buffered in memory. Otherwise, the remainder of r from its current position is
+// read into memory. Gzip-compressed data is detected and parsed like in FetchCountry.
+//
+// The country code cc determines which member of the archive is parsed, e.g. "DE" selects DE.txt.
+func ParseReader(r io.Reader, cc string) ([]Entry, error) {
+	cc, err := normalizeCountryCode(cc)
+	if err != nil {
+		return nil, err
+	}
+
+	ra, size, err := readerAt(r)
+	if err != nil {
+		return nil, fmt.Errorf("read zip data: %w", err)
+	}
+
+	es, _, err := parseZip(ra, size, zippedFile(cc), true, parseConfig{})
+	return es, err
+}
+
+// ParseZipFile parses postal code entries from the GeoNames zip archive at the given path, like ParseReader.
+// The archive is read in place, like with zip.OpenReader, so it is never buffered in memory.
+func ParseZipFile(path, cc string) (_ []Entry, err error) {
 	cc, err = normalizeCountryCode(cc)
 	if err != nil {
-		return
+		return nil, err
 	}
 
-	url := downloadURL(cc)
-	zipData, modified, newEtag, err := download(url, etag)
-	if !modified || err != nil {
-		return
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
 	}
+	defer func(f *os.File) {
+		err = errors.Join(err, f.Close())
+	}(f)
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	es, _, err := parseZip(f, info.Size(), zippedFile(cc), true, parseConfig{})
+	return es, err
+}
 
-	filename := zippedFile(cc)
-	csvData, err := unzipFile(zipData, filename)
+// readerAt returns r as an io.ReaderAt along with its size, as required to read a zip archive.
+// Readers that support random access, such as files, are used directly if positioned at their start,
+// as reading at an offset ignores the position. Other readers are read into memory.
+func readerAt(r io.Reader) (io.ReaderAt, int64, error) {
+	switch ra := r.(type) {
+	case interface {
+		io.ReaderAt
+		Size() int64
+	}:
+		if atStart(r) {
+			return ra, ra.Size(), nil
+		}
+	case *os.File:
+		info, err := ra.Stat()
+		if err == nil && info.Mode().IsRegular() && atStart(r) {
+			return ra, info.Size(), nil
+		}
+	}
+	data, err := io.ReadAll(r)
 	if err != nil {
-		return
+		return nil, 0, err
+	}
+	return bytes.NewReader(data), int64(len(data)), nil
+}
+
+// atStart reports whether r is positioned at its start. Readers that cannot seek are assumed to be.
+func atStart(r io.Reader) bool {
+	s, ok := r.(io.Seeker)
+	if !ok {
+		return true
 	}
+	pos, err := s.Seek(0, io.SeekCurrent)
+	return err == nil && pos == 0
+}
 
-	entries, err = parseCSV(csvData)
+// FetchCountryFiltered fetches postal code entries for a specific country code like FetchCountry,
+// but only returns the entries for which keep returns true.
+// The predicate is applied as the rows are parsed, so discarded entries are never collected.
+// Modified and the new ETag are reported exactly like FetchCountry.
+func FetchCountryFiltered(cc, etag string, keep func(Entry) bool) (entries []Entry, modified bool, newEtag string, err error) {
+	return defaultClient.FetchCountryFiltered(cc, etag, keep)
+}
 
-	return
+// FetchCountryMember fetches postal code entries for a specific country code like FetchCountry,
+// but parses the named member of the archive instead of "<cc>.txt", e.g. for mirrors that package the data
+// differently. If member is empty, the default is used, falling back to another member as for FetchCountry.
+// Otherwise, there is no fallback: if the archive has no such member, FetchCountryMember fails with
+// ErrMemberNotFound, as FetchMember does.
+func FetchCountryMember(cc, etag, member string) (entries []Entry, modified bool, newEtag string, err error) {
+	return defaultClient.FetchCountryMember(cc, etag, member)
+}
+
+// FetchCountryRaw fetches the postal code data for a specific country code like FetchCountry,
+// but returns the decompressed tab-separated text verbatim instead of parsing it,
+// e.g. to persist the file or feed it into another system. The ETag handling is the same as for FetchCountry.
+func FetchCountryRaw(cc, etag string) (data []byte, modified bool, newEtag string, err error) {
+	return defaultClient.FetchCountryRaw(cc, etag)
+}
+
+// CountCountry downloads the postal code data for a specific country code like FetchCountry,
+// but only counts the entries instead of returning them. The rows are counted by scanning the decompressed text
+// for line breaks, so no entries are parsed or held in memory. The ETag handling is the same as for FetchCountry;
+// if the data has not been modified, the count is zero.
+func CountCountry(cc, etag string) (count int, modified bool, newEtag string, err error) {
+	return defaultClient.CountCountry(cc, etag)
+}
+
+// FetchMember fetches the archive for a specific country code like FetchCountry, but returns the content of
+// the named member verbatim, e.g. of readme.txt, which describes the data and its license. Unlike FetchCountry,
+// it fails if the archive has no such member. The ETag handling is the same as for FetchCountry.
+func FetchMember(cc, etag, member string) (data []byte, modified bool, newEtag string, err error) {
+	return defaultClient.FetchMember(cc, etag, member)
+}
+
+// FetchCountryResult is like FetchCountry but returns the result along with metadata taken from the
+// response headers, such as the time the data was last modified.
+func FetchCountryResult(cc, etag string) (FetchResult, error) {
+	return defaultClient.FetchCountryResult(cc, etag)
+}
+
+// FetchCountryContext is like FetchCountryResult but aborts the request, including any retries,
+// once ctx is done.
+func FetchCountryContext(ctx context.Context, cc, etag string) (FetchResult, error) {
+	return defaultClient.FetchCountryContext(ctx, cc, etag)
+}
+
+// FetchCountryTimeout is like FetchCountryResult but aborts the request once the timeout has elapsed.
+// It is a convenience for a per-call timeout without configuring an http.Client. A zero timeout means no timeout.
+func FetchCountryTimeout(cc, etag string, timeout time.Duration) (FetchResult, error) {
+	return defaultClient.FetchCountryTimeout(cc, etag, timeout)
+}
+
+// FetchCountryIfModified is like FetchCountryResult, but additionally sends the If-Modified-Since header
+// if lastModified is not the zero time. See the Client method of the same name for details.
+func FetchCountryIfModified(cc, etag string, lastModified time.Time) (FetchResult, error) {
+	return defaultClient.FetchCountryIfModified(cc, etag, lastModified)
+}
+
+// FetchCountryInto fetches postal code entries for a specific country code like FetchCountry,
+// but appends them to dst, reusing its capacity. Like append, it returns the possibly grown slice.
+// See the Client method of the same name for details.
+func FetchCountryInto(cc, etag string, dst []Entry) (entries []Entry, modified bool, newEtag string, err error) {
+	return defaultClient.FetchCountryInto(cc, etag, dst)
+}
+
+// FetchAll fetches the combined postal code entries of all countries from the GeoNames database.
+// The ETag handling is the same as for FetchCountry.
+//
+// The combined dataset is large. The archive is decompressed while it is being parsed,
+// so the decompressed text is never held in memory in its entirety.
+func FetchAll(etag string) (entries []Entry, modified bool, newEtag string, err error) {
+	return defaultClient.FetchAll(etag)
+}
+
+// FetchAllIndexed fetches the combined postal code entries of all countries like FetchAll, but streams them
+// into an Index as they are parsed instead of returning them, so the entries are not collected in a separate
+// slice before the index is built. The index matches exactly, and its lookups behave the same as those of
+// an index built with NewIndex from the entries returned by FetchAll.
+// If the data has not been modified, the index is nil. The ETag handling is the same as for FetchCountry.
+func FetchAllIndexed(etag string) (idx *Index, modified bool, newEtag string, err error) {
+	return defaultClient.FetchAllIndexed(etag)
 }
 
 func normalizeCountryCode(cc string) (string, error) {
@@ -104,92 +264,361 @@ func normalizeCountryCode(cc string) (string, error) {
 	if got, want := len(cc), 2; got != want {
 		return r, fmt.Errorf("country code %q has %d bytes, want %d", cc, got, want)
 	}
+	if _, ok := iso3166[r]; !ok {
+		return r, fmt.Errorf("unknown country code %q", cc)
+	}
 	return r, nil
 }
 
-func downloadURL(cc string) string {
-	return fmt.Sprintf("https://download.geonames.org/export/zip/%s.zip", cc)
+// allCountries is the name of the combined dataset of all countries.
+const allCountries = "allCountries"
+
+func zippedFile(cc string) string {
+	return fmt.Sprintf("%s.txt", cc)
 }
 
-func download(url, etag string) (_ []byte, _ bool, _ string, err error) {
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+// memberInfo describes the archive member parsed by parseZip.
+type memberInfo struct {
+	// name is the name of the member.
+	name string
+	// size is the number of decompressed bytes read from the member.
+	size int64
+}
+
+// parseZip parses the named member of the archive read from r, falling back to another member like unzipFile
+// if fallback is set. It returns the entries along with a description of the parsed member.
+func parseZip(r io.ReaderAt, size int64, filename string, fallback bool, cfg parseConfig) (_ []Entry, member memberInfo, err error) {
+	rc, name, err := openMember(r, size, filename, fallback)
 	if err != nil {
-		return nil, false, "", err
+		return nil, memberInfo{}, err
+	}
+	defer func(rc io.ReadCloser) {
+		err = errors.Join(err, rc.Close())
+	}(rc)
+
+	counter := &countingReader{r: rc}
+	var data io.Reader = counter
+	if cfg.hash != nil {
+		data = io.TeeReader(counter, cfg.hash)
 	}
-	req.Header.Add("If-None-Match", etag)
-	resp, err := HTTPClient.Do(req)
+	es, err := parseCSV(data, cfg)
 	if err != nil {
-		return nil, false, "", err
+		return nil, memberInfo{}, withLineContext(err, func() (io.ReadCloser, error) {
+			return unzipFile(r, size, name)
+		})
 	}
-	defer func(Body io.ReadCloser) {
-		if closeErr := Body.Close(); closeErr != nil {
-			err = closeErr
-		}
-	}(resp.Body)
+	return es, memberInfo{name: name, size: counter.read}, nil
+}
 
-	if resp.StatusCode == http.StatusNotModified {
-		// No new codes and no error.
-		return nil, false, etag, nil
-	}
+// readmeFile is the name of the member describing the dataset, which GeoNames ships with every archive.
+const readmeFile = "readme.txt"
+
+// unzipFile opens the named member of the archive read from r, which is usually a zip archive,
+// but may be in any of the formats detected by openArchive.
+// If the archive has no such member, but exactly one .txt member other than the readme, that member is opened
+// instead, as some archives name their data differently. A member named after another country, like DE.txt,
+// or allCountries.txt is never opened instead, as it holds the data of a different dataset.
+// The member is decompressed as it is read, so it is never buffered in its entirety.
+func unzipFile(r io.ReaderAt, size int64, filename string) (io.ReadCloser, error) {
+	rc, _, err := openMember(r, size, filename, true)
+	return rc, err
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, false, "", fmt.Errorf("status = %s, want 200", resp.Status)
+// openMember opens the named member of the archive read from r, as unzipFile does, and returns the name
+// of the opened member. The fallback to another .txt member is only used if fallback is set.
+func openMember(r io.ReaderAt, size int64, filename string, fallback bool) (io.ReadCloser, string, error) {
+	a, err := openArchive(r, size, filename)
+	if err != nil {
+		return nil, "", err
+	}
+	found := false
+	var others []string
+	for _, name := range a.names() {
+		if name == filename {
+			found = true
+			break
+		}
+		if strings.HasSuffix(name, ".txt") && name != readmeFile {
+			others = append(others, name)
+		}
+	}
+	if !found && fallback && len(others) == 1 && !isCountryFile(others[0]) {
+		filename, found = others[0], true
+	}
+	if !found {
+		return nil, "", fmt.Errorf("%w: zipfile missing %s", ErrMemberNotFound, filename)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	rc, err := a.open(filename)
 	if err != nil {
-		return nil, false, "", fmt.Errorf("read response body: %w", err)
+		return nil, "", fmt.Errorf("%w: open zipped %s: %w", ErrInvalidArchive, filename, err)
 	}
+	return archiveReader{rc}, filename, nil
+}
 
-	return body, true, resp.Header.Get("Etag"), nil
+// isCountryFile reports whether name is the name of the data file of a country, like DE.txt,
+// or of the combined dataset of all countries.
+func isCountryFile(name string) bool {
+	base := strings.TrimSuffix(name, ".txt")
+	if base == allCountries {
+		return true
+	}
+	_, ok := iso3166[base]
+	return ok
 }
 
-func zippedFile(cc string) string {
-	return fmt.Sprintf("%s.txt", cc)
+// archiveReader reads an archive member, marking errors other than io.EOF, such as checksum errors
+// of a corrupt archive, with ErrInvalidArchive.
+type archiveReader struct {
+	io.ReadCloser
+}
+
+func (r archiveReader) Read(b []byte) (int, error) {
+	n, err := r.ReadCloser.Read(b)
+	if err != nil && err != io.EOF {
+		err = fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+	}
+	return n, err
 }
 
-func unzipFile(data []byte, filename string) (_ []byte, err error) {
-	unzip, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+// matchMember returns the name of the single member of the archive read from r that matches the glob pattern,
+// as understood by path.Match. The readme is never matched. It fails if no member or more than one member matches.
+func matchMember(r io.ReaderAt, size int64, pattern string) (string, error) {
+	matches, err := matchMembers(r, size, pattern)
 	if err != nil {
-		return nil, fmt.Errorf("create unzipping reader: %w", err)
+		return "", err
 	}
-	var file *zip.File
-	for _, f := range unzip.File {
-		if f.Name == filename {
-			file = f
-			break
+	if len(matches) > 1 {
+		return "", fmt.Errorf("zipfile has %d members matching %q: %s", len(matches), pattern, strings.Join(matches, ", "))
+	}
+	return matches[0], nil
+}
+
+// matchMembers returns the names of the members of the archive read from r that match the glob pattern,
+// as understood by path.Match, in sorted order. If pattern is empty, all .txt members match.
+// The readme is never matched. It fails if no member matches.
+func matchMembers(r io.ReaderAt, size int64, pattern string) ([]string, error) {
+	a, err := openArchive(r, size, "")
+	if err != nil {
+		return nil, err
+	}
+	var matches []string
+	for _, name := range a.names() {
+		ok := strings.HasSuffix(name, ".txt")
+		if pattern != "" {
+			if ok, err = path.Match(pattern, name); err != nil {
+				return nil, fmt.Errorf("match member %q: %w", pattern, err)
+			}
 		}
+		if ok && name != readmeFile {
+			matches = append(matches, name)
+		}
+	}
+	if len(matches) == 0 {
+		if pattern == "" {
+			return nil, fmt.Errorf("%w: zipfile has no .txt member", ErrMemberNotFound)
+		}
+		return nil, fmt.Errorf("%w: zipfile has no member matching %q", ErrMemberNotFound, pattern)
 	}
-	if file == nil {
-		return nil, fmt.Errorf("zipfile missing %s", filename)
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// ParseStream parses tab-separated postal code data, as found in the members of GeoNames zip archives,
+// from r and calls fn for each entry as it is read. This avoids holding all entries in memory at once.
+//
+// All rows must have the same number of fields. If they have fewer than 12, the remaining fields of the entries
+// are empty. If they have more, parsing fails with an error wrapping ErrTooManyFields, rather than losing the
+// extra data.
+//
+// Parsing stops at the first error returned by fn, which is then returned unchanged.
+func ParseStream(r io.Reader, fn func(Entry) error) error {
+	return parseStream(r, 0, func(_ int, e Entry) error {
+		return fn(e)
+	})
+}
+
+// parseStream parses r like ParseStream, but also passes the 1-based line number on which each entry starts to fn.
+// The number of fields per row is checked as configured by fieldsPerRecord, see csv.Reader.FieldsPerRecord.
+func parseStream(r io.Reader, fieldsPerRecord int, fn func(line int, e Entry) error) error {
+	reader := newCSVReader(r)
+	reader.FieldsPerRecord = fieldsPerRecord
+	for {
+		columns, err := readRecord(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(columns) > numFields {
+			// Extra columns would be lost, e.g. if GeoNames extended the format.
+			line, column := reader.FieldPos(numFields)
+			return &csv.ParseError{StartLine: line, Line: line, Column: column,
+				Err: fmt.Errorf("%w: got %d, want at most %d", ErrTooManyFields, len(columns), numFields)}
+		}
+		line, _ := reader.FieldPos(0)
+		if err := fn(line, newEntry(columns)); err != nil {
+			return err
+		}
 	}
+}
 
-	rc, err := file.Open()
+// IndexedEntry is an entry along with the line of the source data it was parsed from.
+type IndexedEntry struct {
+	// Line is the 1-based line number on which the entry starts.
+	Line int
+	// Entry is the parsed entry.
+	Entry Entry
+}
+
+// ParseIndexed parses tab-separated postal code data like ParseStream, but tags each entry with its line number,
+// e.g. to report problems with the data back to its maintainers with exact line references.
+// Empty lines are skipped, so line numbers may have gaps.
+func ParseIndexed(data []byte) ([]IndexedEntry, error) {
+	es := make([]IndexedEntry, 0)
+	err := parseStream(bytes.NewReader(data), 0, func(line int, e Entry) error {
+		es = append(es, IndexedEntry{Line: line, Entry: e})
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("open zipped %s: %w", filename, err)
+		return nil, withLineContext(err, openBytes(data))
 	}
-	defer func(rc io.ReadCloser) {
-		err = errors.Join(err, rc.Close())
-	}(rc)
+	return es, nil
+}
+
+// ParseStrict parses tab-separated postal code data like ParseStream, but fails on malformed input.
+// Unlike the lenient parsing used by FetchCountry, every row must have exactly 12 fields.
+// Otherwise, an error identifying the 1-based row number and the actual number of fields is returned.
+func ParseStrict(data []byte) ([]Entry, error) {
+	reader := newCSVReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	es := make([]Entry, 0)
+	for row := 1; ; row++ {
+		columns, err := readRecord(reader)
+		if err == io.EOF {
+			return es, nil
+		}
+		if err != nil {
+			return nil, withLineContext(err, openBytes(data))
+		}
+		if got, want := len(columns), numFields; got != want {
+			return nil, fmt.Errorf("row %d has %d fields, want %d", row, got, want)
+		}
+		es = append(es, newEntry(columns))
+	}
+}
 
-	return io.ReadAll(rc)
+// ParseOptions configures ParseWithOptions. The zero value matches the tab-separated format used by GeoNames.
+type ParseOptions struct {
+	// Comma is the field delimiter. If zero, a tab is used.
+	Comma rune
+	// LazyQuotes tolerates quotes in unquoted fields and non-doubled quotes in quoted fields.
+	LazyQuotes bool
+	// FieldsPerRecord is the number of fields expected per row, as for csv.Reader:
+	// If zero, every row must have the same number of fields as the first.
+	// If negative, rows may have a variable number of fields.
+	FieldsPerRecord int
 }
 
-func parseCSV(data []byte) ([]Entry, error) {
-	r := bytes.NewReader(data)
-	reader := csv.NewReader(r)
+// ParseWithOptions parses postal code data in a variant format described by opts.
+// Rows with more than 12 fields are rejected, as they do not fit into an Entry.
+func ParseWithOptions(data []byte, opts ParseOptions) ([]Entry, error) {
+	reader := newCSVReader(bytes.NewReader(data))
+	if opts.Comma != 0 {
+		reader.Comma = opts.Comma
+	}
+	reader.LazyQuotes = opts.LazyQuotes
+	reader.FieldsPerRecord = opts.FieldsPerRecord
+	es := make([]Entry, 0)
+	for row := 1; ; row++ {
+		columns, err := readRecord(reader)
+		if err == io.EOF {
+			return es, nil
+		}
+		if err != nil {
+			return nil, withLineContext(err, openBytes(data))
+		}
+		if got, limit := len(columns), numFields; got > limit {
+			return nil, fmt.Errorf("row %d has %d fields, want at most %d: %w", row, got, limit, ErrTooManyFields)
+		}
+		es = append(es, newEntry(columns))
+	}
+}
+
+// openBytes returns a function opening data for withLineContext.
+func openBytes(data []byte) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+}
+
+// utf8BOM is the byte order mark some editors prepend to UTF-8 files.
+var utf8BOM = []byte("\ufeff")
+
+// newCSVReader returns a reader for tab-separated postal code data from r, skipping a leading UTF-8 BOM.
+func newCSVReader(r io.Reader) *csv.Reader {
+	br := bufio.NewReader(r)
+	if prefix, err := br.Peek(len(utf8BOM)); err == nil && bytes.Equal(prefix, utf8BOM) {
+		_, _ = br.Discard(len(utf8BOM))
+	}
+	reader := csv.NewReader(br)
 	reader.Comma = '\t'
-	table, err := reader.ReadAll()
+	reader.ReuseRecord = true
+	return reader
+}
+
+// readRecord reads the next row from reader. Since GeoNames data is encoded as UTF-8, a row with a field that is
+// not valid UTF-8 is rejected with a *csv.ParseError wrapping ErrInvalidUTF8, rather than silently kept.
+func readRecord(reader *csv.Reader) ([]string, error) {
+	columns, err := reader.Read()
 	if err != nil {
 		return nil, err
 	}
-	es := make([]Entry, len(table))
-	for i, columns := range table {
-		var e Entry
-		for ii, col := range columns {
-			e[ii] = col
+	for i, col := range columns {
+		if !utf8.ValidString(col) {
+			line, column := reader.FieldPos(i)
+			return nil, &csv.ParseError{StartLine: line, Line: line, Column: column, Err: ErrInvalidUTF8}
 		}
-		es[i] = e
+	}
+	return columns, nil
+}
+
+func newEntry(columns []string) Entry {
+	var e Entry
+	for i, col := range columns {
+		e[i] = col
+	}
+	return e
+}
+
+// parseConfig configures how parseCSV collects entries.
+type parseConfig struct {
+	// keep, if not nil, selects the entries to collect.
+	keep func(Entry) bool
+	// dst, if not nil, is the slice the entries are appended to.
+	dst []Entry
+	// hash, if not nil, is written the decompressed data as it is parsed, e.g. to compute its checksum.
+	hash io.Writer
+	// fieldsPerRecord, if positive, is the number of fields expected per row. By default, it is the number
+	// of fields of the first row, see csv.Reader.FieldsPerRecord.
+	fieldsPerRecord int
+}
+
+// parseCSV parses all entries from r and collects them as configured by cfg.
+func parseCSV(r io.Reader, cfg parseConfig) ([]Entry, error) {
+	es := cfg.dst
+	if es == nil {
+		es = make([]Entry, 0)
+	}
+	err := parseStream(r, cfg.fieldsPerRecord, func(_ int, e Entry) error {
+		if cfg.keep == nil || cfg.keep(e) {
+			es = append(es, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return es, nil
 }
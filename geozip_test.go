@@ -1,10 +1,17 @@
 package geozip_test
 
 import (
-	"github.com/ngrash/geozip"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
 	"net/http"
 	"os"
+	"strings"
 	"testing"
+
+	"github.com/ngrash/geozip"
+	"github.com/ngrash/geozip/geoziptest"
 )
 
 type RoundTripperFunc func(*http.Request) (*http.Response, error)
@@ -13,8 +20,27 @@ func (fn RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error)
 	return fn(req)
 }
 
+// zipArchive returns a zip archive containing the given members, sorted by name.
+func zipArchive(t *testing.T, members map[string]string) []byte {
+	t.Helper()
+	return geoziptest.Archive(t, members)
+}
+
+// serveBytes returns a transport that responds to every request with data and the given ETag.
+func serveBytes(data []byte, etag string) RoundTripperFunc {
+	return func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewReader(data)),
+			Header: http.Header{
+				"Etag": []string{etag},
+			},
+		}, nil
+	}
+}
+
 func TestFetchCountry_NotModified(t *testing.T) {
-	const requestEtag = "current_etag"
+	const requestEtag = `"current_etag"`
 	geozip.HTTPClient.Transport = RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
 		if got, want := r.URL.String(), "https://download.geonames.org/export/zip/DE.zip"; got != want {
 			t.Errorf("client requested %q, want %q", got, want)
@@ -44,10 +70,33 @@ func TestFetchCountry_NotModified(t *testing.T) {
 	}
 }
 
+func TestFetchCountry_Empty(t *testing.T) {
+	data := zipArchive(t, map[string]string{
+		"readme.txt": "readme",
+		"AQ.txt":     "",
+	})
+	geozip.HTTPClient.Transport = serveBytes(data, `"new_etag"`)
+	defer func() { geozip.HTTPClient.Transport = nil }()
+
+	entries, modified, newEtag, err := geozip.FetchCountry("AQ", "")
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if entries == nil || len(entries) != 0 {
+		t.Errorf("entries = %#v, want non-nil empty slice", entries)
+	}
+	if !modified {
+		t.Error("modified = false, want true")
+	}
+	if got, want := newEtag, `"new_etag"`; got != want {
+		t.Errorf("newEtag = %v, want %v", got, want)
+	}
+}
+
 func TestFetchCountry_Modified(t *testing.T) {
 	const (
-		requestEtag  = "old_etag"
-		responseEtag = "new_etag"
+		requestEtag  = `"old_etag"`
+		responseEtag = `"new_etag"`
 	)
 	geozip.HTTPClient.Transport = RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
 		if got, want := r.URL.String(), "https://download.geonames.org/export/zip/DE.zip"; got != want {
@@ -134,3 +183,368 @@ func TestFetchCountry_Modified(t *testing.T) {
 		}
 	}
 }
+
+func TestParseReader(t *testing.T) {
+	file, err := os.Open("test_data/DE.zip")
+	if err != nil {
+		t.Fatal("open test data", err)
+	}
+	defer file.Close()
+
+	entries, err := geozip.ParseReader(file, "de")
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if got, want := len(entries), 16477; got != want {
+		t.Errorf("len(entries) = %v, want %v", got, want)
+	}
+}
+
+func TestParseZipFile(t *testing.T) {
+	entries, err := geozip.ParseZipFile("test_data/DE.zip", "DE")
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if got, want := len(entries), 16477; got != want {
+		t.Errorf("len(entries) = %v, want %v", got, want)
+	}
+
+	if _, err := geozip.ParseZipFile("test_data/missing.zip", "DE"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing file: err = %v, want %v", err, os.ErrNotExist)
+	}
+}
+
+func TestParseReader_MissingMember(t *testing.T) {
+	file, err := os.Open("test_data/DE.zip")
+	if err != nil {
+		t.Fatal("open test data", err)
+	}
+	defer file.Close()
+
+	if _, err := geozip.ParseReader(file, "US"); err == nil {
+		t.Error("err = nil, want error for missing US.txt")
+	}
+}
+
+func TestParseReader_NoFallback(t *testing.T) {
+	tests := []struct {
+		name    string
+		members map[string]string
+	}{
+		{"readme only", map[string]string{"readme.txt": "readme"}},
+		{"other country", map[string]string{"readme.txt": "readme", "DE.txt": "DE\t54668\tFerschweiler\n"}},
+		{"all countries", map[string]string{"allCountries.txt": "DE\t54668\tFerschweiler\n"}},
+		{"several members", map[string]string{"a.txt": "AT\t1010\tWien\n", "b.txt": "AT\t1010\tWien\n"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := zipArchive(t, tt.members)
+			if _, err := geozip.ParseReader(bytes.NewReader(data), "AT"); !errors.Is(err, geozip.ErrMemberNotFound) {
+				t.Errorf("err = %v, want %v", err, geozip.ErrMemberNotFound)
+			}
+		})
+	}
+}
+
+func TestFetchAll(t *testing.T) {
+	data := zipArchive(t, map[string]string{
+		"allCountries.txt": "AD\tAD100\tCanillo\t\t\t\t\t\t\t42.5833\t1.6667\t6\n" +
+			"DE\t54668\tFerschweiler\tRheinland-Pfalz\tRP\t\t00\tEifelkreis Bitburg-Prüm\t07232\t49.8667\t6.4\t4\n",
+	})
+	transport := serveBytes(data, `"new_etag"`)
+	geozip.HTTPClient.Transport = RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if got, want := r.URL.String(), "https://download.geonames.org/export/zip/allCountries.zip"; got != want {
+			t.Errorf("client requested %q, want %q", got, want)
+		}
+		return transport(r)
+	})
+	defer func() { geozip.HTTPClient.Transport = nil }()
+
+	entries, modified, newEtag, err := geozip.FetchAll("")
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if !modified {
+		t.Error("modified = false, want true")
+	}
+	if got, want := newEtag, `"new_etag"`; got != want {
+		t.Errorf("newEtag = %v, want %v", got, want)
+	}
+	if got, want := len(entries), 2; got != want {
+		t.Fatalf("len(entries) = %v, want %v", got, want)
+	}
+	if got, want := entries[1][geozip.PlaceName], "Ferschweiler"; got != want {
+		t.Errorf("entries[1]: PlaceName = %v, want %v", got, want)
+	}
+}
+
+func TestParseReader_FallbackMember(t *testing.T) {
+	data := zipArchive(t, map[string]string{
+		"readme.txt":   "readme",
+		"regional.txt": "XK\t10000\tPristina\n",
+	})
+
+	entries, err := geozip.ParseReader(bytes.NewReader(data), "XK")
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if got, want := len(entries), 1; got != want {
+		t.Fatalf("len(entries) = %v, want %v", got, want)
+	}
+	if got, want := entries[0][geozip.PlaceName], "Pristina"; got != want {
+		t.Errorf("entries[0]: PlaceName = %v, want %v", got, want)
+	}
+}
+
+func TestParseReader_BOM(t *testing.T) {
+	data := zipArchive(t, map[string]string{
+		"DE.txt": "\ufeffDE\t54595\tPrüm\n",
+	})
+
+	entries, err := geozip.ParseReader(bytes.NewReader(data), "DE")
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if got, want := len(entries), 1; got != want {
+		t.Fatalf("len(entries) = %v, want %v", got, want)
+	}
+	if got, want := entries[0][geozip.CountryCode], "DE"; got != want {
+		t.Errorf("entries[0]: CountryCode = %q, want %q", got, want)
+	}
+	if got, want := entries[0][geozip.PlaceName], "Prüm"; got != want {
+		t.Errorf("entries[0]: PlaceName = %q, want %q", got, want)
+	}
+}
+
+func TestParseReader_InvalidUTF8(t *testing.T) {
+	data := zipArchive(t, map[string]string{
+		"DE.txt": "DE\t54668\tFerschweiler\n" +
+			"DE\t54595\tPr\xfcm\n", // Latin-1
+	})
+
+	_, err := geozip.ParseReader(bytes.NewReader(data), "DE")
+	if !errors.Is(err, geozip.ErrInvalidUTF8) {
+		t.Fatalf("err = %v, want %v", err, geozip.ErrInvalidUTF8)
+	}
+	var perr *geozip.ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("err = %T, want *geozip.ParseError", err)
+	}
+	if got, want := perr.Line, 2; got != want {
+		t.Errorf("Line = %v, want %v", got, want)
+	}
+}
+
+func TestParseStream(t *testing.T) {
+	const data = "DE\t54668\tFerschweiler\n" +
+		"DE\t56479\tNeustadt (Westerwald)\n"
+
+	var names []string
+	err := geozip.ParseStream(strings.NewReader(data), func(e geozip.Entry) error {
+		names = append(names, e[geozip.PlaceName])
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if got, want := strings.Join(names, ","), "Ferschweiler,Neustadt (Westerwald)"; got != want {
+		t.Errorf("place names = %v, want %v", got, want)
+	}
+}
+
+func TestParseStream_TooManyFields(t *testing.T) {
+	const data = "DE\t56479\tRehe\tRheinland-Pfalz\tRP\t\t00\tWesterwaldkreis\t07143\t50.6333\t8.0667\t4\textra\n"
+
+	err := geozip.ParseStream(strings.NewReader(data), func(geozip.Entry) error { return nil })
+	if !errors.Is(err, geozip.ErrTooManyFields) {
+		t.Fatalf("err = %v, want %v", err, geozip.ErrTooManyFields)
+	}
+	var perr *csv.ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("err = %T, want *csv.ParseError", err)
+	}
+	if got, want := perr.Column, 77; got != want {
+		t.Errorf("Column = %v, want %v", got, want)
+	}
+}
+
+func TestParseStream_StopEarly(t *testing.T) {
+	const data = "DE\t54668\tFerschweiler\n" +
+		"DE\t56479\tNeustadt (Westerwald)\n"
+	stop := errors.New("stop")
+
+	calls := 0
+	err := geozip.ParseStream(strings.NewReader(data), func(e geozip.Entry) error {
+		calls++
+		return stop
+	})
+	if err != stop {
+		t.Errorf("err = %v, want %v", err, stop)
+	}
+	if got, want := calls, 1; got != want {
+		t.Errorf("fn called %d times, want %d", got, want)
+	}
+}
+
+func TestParseIndexed(t *testing.T) {
+	const data = "\ufeffDE\t54668\tFerschweiler\n\nDE\t54636\t\"Bit\nburg\"\nDE\t54634\tBitburg\n"
+
+	entries, err := geozip.ParseIndexed([]byte(data))
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	want := []struct {
+		line       int
+		postalCode string
+	}{{1, "54668"}, {3, "54636"}, {5, "54634"}}
+	if got, want := len(entries), len(want); got != want {
+		t.Fatalf("len(entries) = %v, want %v", got, want)
+	}
+	for i, w := range want {
+		if got := entries[i]; got.Line != w.line || got.Entry[geozip.PostalCode] != w.postalCode {
+			t.Errorf("entries[%d] = line %d, %s, want line %d, %s", i, got.Line, got.Entry[geozip.PostalCode], w.line, w.postalCode)
+		}
+	}
+}
+
+func TestParseIndexed_Error(t *testing.T) {
+	const data = "DE\t54668\tFerschweiler\nDE\t54636\n"
+
+	_, err := geozip.ParseIndexed([]byte(data))
+	var perr *csv.ParseError
+	if !errors.As(err, &perr) || perr.Line != 2 {
+		t.Errorf("err = %v, want *csv.ParseError on line 2", err)
+	}
+}
+
+func TestParseStrict(t *testing.T) {
+	const data = "DE\t54668\tFerschweiler\tRheinland-Pfalz\tRP\t\t00\tEifelkreis Bitburg-Prüm\t07232\t49.8667\t6.4\t4\n"
+
+	entries, err := geozip.ParseStrict([]byte(data))
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if got, want := len(entries), 1; got != want {
+		t.Fatalf("len(entries) = %v, want %v", got, want)
+	}
+	if got, want := entries[0][geozip.Accuracy], "4"; got != want {
+		t.Errorf("entries[0]: Accuracy = %v, want %v", got, want)
+	}
+}
+
+func TestParseStrict_WrongFieldCount(t *testing.T) {
+	const data = "DE\t54668\tFerschweiler\tRheinland-Pfalz\tRP\t\t00\tEifelkreis Bitburg-Prüm\t07232\t49.8667\t6.4\t4\n" +
+		"DE\t56479\tNeustadt (Westerwald)\n"
+
+	_, err := geozip.ParseStrict([]byte(data))
+	if err == nil {
+		t.Fatal("err = nil, want error")
+	}
+	if got, want := err.Error(), "row 2 has 3 fields, want 12"; got != want {
+		t.Errorf("err = %q, want %q", got, want)
+	}
+}
+
+func TestParseWithOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		opts    geozip.ParseOptions
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			data: "DE\t54668\tFerschweiler\n",
+			want: []string{"Ferschweiler"},
+		},
+		{
+			name: "comma",
+			data: "DE;54668;Ferschweiler\n",
+			opts: geozip.ParseOptions{Comma: ';'},
+			want: []string{"Ferschweiler"},
+		},
+		{
+			name:    "stray quote",
+			data:    "DE\t54668\tFersch\"weiler\n",
+			wantErr: true,
+		},
+		{
+			name: "lazy quotes",
+			data: "DE\t54668\tFersch\"weiler\n",
+			opts: geozip.ParseOptions{LazyQuotes: true},
+			want: []string{"Fersch\"weiler"},
+		},
+		{
+			name:    "fields per record",
+			data:    "DE\t54668\tFerschweiler\n",
+			opts:    geozip.ParseOptions{FieldsPerRecord: 12},
+			wantErr: true,
+		},
+		{
+			name: "variable fields",
+			data: "DE\t54668\tFerschweiler\nDE\t56479\n",
+			opts: geozip.ParseOptions{FieldsPerRecord: -1},
+			want: []string{"Ferschweiler", ""},
+		},
+		{
+			name:    "too many fields",
+			data:    strings.Repeat("x\t", 12) + "x\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := geozip.ParseWithOptions([]byte(tt.data), tt.opts)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("err = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v, want nil", err)
+			}
+			if got, want := len(entries), len(tt.want); got != want {
+				t.Fatalf("len(entries) = %v, want %v", got, want)
+			}
+			for i, e := range entries {
+				if got, want := e[geozip.PlaceName], tt.want[i]; got != want {
+					t.Errorf("entries[%d]: PlaceName = %q, want %q", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkParseReader(b *testing.B) {
+	b.Run("File", func(b *testing.B) {
+		file, err := os.Open("test_data/DE.zip")
+		if err != nil {
+			b.Fatal("open test data", err)
+		}
+		defer file.Close()
+
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := geozip.ParseReader(file, "DE"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Stream", func(b *testing.B) {
+		data, err := os.ReadFile("test_data/DE.zip")
+		if err != nil {
+			b.Fatal("read test data", err)
+		}
+
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			// Hide the io.ReaderAt implementation of bytes.Reader.
+			r := io.MultiReader(bytes.NewReader(data))
+			if _, err := geozip.ParseReader(r, "DE"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,104 @@
+// Package geoziptest provides utilities for testing code that uses geozip, without network access.
+package geoziptest
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ngrash/geozip"
+)
+
+// ServeZip replaces geozip.HTTPClient with a client that serves the given zip archives, keyed by country code,
+// for the duration of the test. See Transport for how requests are answered.
+// The previous client is restored when the test and its subtests complete.
+//
+// Since geozip.HTTPClient is global, tests using ServeZip must not run in parallel.
+// Use Transport with a geozip.Client instead where that matters.
+func ServeZip(t testing.TB, fixtures map[string][]byte) {
+	t.Helper()
+	prev := geozip.HTTPClient
+	geozip.HTTPClient = &http.Client{Transport: Transport(fixtures)}
+	t.Cleanup(func() { geozip.HTTPClient = prev })
+}
+
+// Transport returns a transport that serves the given zip archives, keyed by country code, e.g. "DE" for DE.zip,
+// or "allCountries". Each archive is served with an ETag derived from its content, and with 304 Not Modified
+// if the request carries that ETag in its If-None-Match header. Requests for other archives result in 404 Not Found.
+//
+// The host and directory of the requested URL are ignored, so the transport works with any base URL.
+func Transport(fixtures map[string][]byte) http.RoundTripper {
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		name := strings.TrimSuffix(path.Base(r.URL.Path), ".zip")
+		data, ok := fixtures[name]
+		if !ok {
+			return response(r, http.StatusNotFound, nil, nil), nil
+		}
+		etag := fmt.Sprintf(`"%x"`, sha256.Sum256(data))
+		header := http.Header{"Etag": []string{etag}}
+		if r.Header.Get("If-None-Match") == etag {
+			return response(r, http.StatusNotModified, header, nil), nil
+		}
+		header.Set("Content-Length", fmt.Sprint(len(data)))
+		if r.Method == http.MethodHead {
+			return response(r, http.StatusOK, header, nil), nil
+		}
+		return response(r, http.StatusOK, header, data), nil
+	})
+}
+
+// Archive returns a zip archive holding the given members, keyed by name, e.g. "DE.txt".
+// The members are stored sorted by name, so the same members always result in the same archive,
+// which is served with the same ETag by Transport.
+func Archive(t testing.TB, members map[string]string) []byte {
+	t.Helper()
+	names := make([]string, 0, len(members))
+	for name := range members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, name := range names {
+		content := members[name]
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal("create zip member", err)
+		}
+		if _, err := io.WriteString(f, content); err != nil {
+			t.Fatal("write zip member", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal("close zip writer", err)
+	}
+	return buf.Bytes()
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func response(r *http.Request, code int, header http.Header, body []byte) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		StatusCode:    code,
+		Status:        fmt.Sprintf("%d %s", code, http.StatusText(code)),
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       r,
+	}
+}
@@ -0,0 +1,64 @@
+package geoziptest_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ngrash/geozip"
+	"github.com/ngrash/geozip/geoziptest"
+)
+
+func TestServeZip(t *testing.T) {
+	geoziptest.ServeZip(t, map[string][]byte{
+		"DE": geoziptest.Archive(t, map[string]string{"DE.txt": "DE\t54668\tFerschweiler\n"}),
+	})
+
+	entries, modified, etag, err := geozip.FetchCountry("de", "")
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if !modified || len(entries) != 1 || entries[0][geozip.PlaceName] != "Ferschweiler" {
+		t.Errorf("modified, entries = %v, %v, want true and Ferschweiler", modified, entries)
+	}
+	if etag == "" {
+		t.Error("etag is empty")
+	}
+
+	entries, modified, newEtag, err := geozip.FetchCountry("DE", etag)
+	if err != nil || modified || entries != nil || newEtag != etag {
+		t.Errorf("not modified: entries, modified, etag, err = %v, %v, %v, %v, want nil, false, %v, nil",
+			entries, modified, newEtag, err, etag)
+	}
+
+	if _, _, _, err := geozip.FetchCountry("AT", ""); !errors.Is(err, geozip.ErrCountryNotFound) {
+		t.Errorf("missing fixture: err = %v, want %v", err, geozip.ErrCountryNotFound)
+	}
+}
+
+func TestTransport_CountryExists(t *testing.T) {
+	client := geozip.New(geozip.WithHTTPClient(&http.Client{Transport: geoziptest.Transport(map[string][]byte{
+		"DE": geoziptest.Archive(t, map[string]string{"DE.txt": ""}),
+	})}))
+
+	for cc, want := range map[string]bool{"DE": true, "AT": false} {
+		exists, err := client.CountryExists(cc)
+		if err != nil {
+			t.Fatalf("CountryExists(%q): err = %v, want nil", cc, err)
+		}
+		if exists != want {
+			t.Errorf("CountryExists(%q) = %v, want %v", cc, exists, want)
+		}
+	}
+}
+
+func TestArchive_Deterministic(t *testing.T) {
+	members := map[string]string{"DE.txt": "DE\t54668\tFerschweiler\n", "readme.txt": "license", "a.txt": "", "z.txt": ""}
+	want := geoziptest.Archive(t, members)
+	for i := 0; i < 10; i++ {
+		if got := geoziptest.Archive(t, members); !bytes.Equal(got, want) {
+			t.Fatalf("Archive differs between calls with the same members")
+		}
+	}
+}
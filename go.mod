@@ -1,5 +1,7 @@
 module github.com/ngrash/geozip
 
-go 1.21
+go 1.23.0
 
-retract v0.1.0 // Published under old package name.
\ No newline at end of file
+retract v0.1.0 // Published under old package name.
+
+require golang.org/x/text v0.28.0
@@ -0,0 +1,264 @@
+package geozip
+
+import (
+	"sort"
+	"strings"
+)
+
+// Index provides fast lookups of postal code entries.
+// Build it once with NewIndex or NewIndexFold and query it many times.
+type Index struct {
+	// entries holds each entry once. The lookup maps hold positions in entries, in input order.
+	entries      []Entry
+	fold         bool
+	byPostalCode map[string][]int
+	byPlaceName  map[string][]int
+	// byFoldedName maps lower-case place names to entries for prefix searches.
+	byFoldedName map[string][]int
+	// foldedNames holds the keys of byFoldedName in sorted order.
+	foldedNames []string
+}
+
+// NewIndex builds an index over the given entries. Lookups match exactly.
+func NewIndex(entries []Entry) *Index {
+	return newIndex(entries, false)
+}
+
+// NewIndexFold builds an index over the given entries like NewIndex,
+// but place names and postal codes are matched case-insensitively.
+// Postal codes are additionally matched regardless of whitespace, so "ec1a 1bb" and "EC1A1BB" both match "EC1A 1BB".
+//
+// This matters for countries with alphanumeric postal codes, such as GB, NL ("1012 AB"), CA ("K1A 0B1"),
+// IE, MT or AR, whose codes users tend to enter in lower case or with varying spaces.
+// Purely numeric postal codes, as in DE or US, match the same as with NewIndex.
+func NewIndexFold(entries []Entry) *Index {
+	return newIndex(entries, true)
+}
+
+func newIndex(entries []Entry, fold bool) *Index {
+	idx := emptyIndex(fold)
+	idx.entries = entries
+	for i := range entries {
+		idx.add(i)
+	}
+	idx.sortNames()
+	return idx
+}
+
+// emptyIndex returns an index without entries, to be filled with add and completed with sortNames.
+func emptyIndex(fold bool) *Index {
+	return &Index{
+		fold:         fold,
+		byPostalCode: make(map[string][]int),
+		byPlaceName:  make(map[string][]int),
+		byFoldedName: make(map[string][]int),
+	}
+}
+
+// add adds the entry at position i of idx.entries to the lookup maps of the index.
+func (idx *Index) add(i int) {
+	e := &idx.entries[i]
+	code := idx.postalCodeKey(e[PostalCode])
+	idx.byPostalCode[code] = append(idx.byPostalCode[code], i)
+	name := idx.placeNameKey(e[PlaceName])
+	idx.byPlaceName[name] = append(idx.byPlaceName[name], i)
+	folded := strings.ToLower(e[PlaceName])
+	idx.byFoldedName[folded] = append(idx.byFoldedName[folded], i)
+}
+
+// collect appends the entries at the given positions of idx.entries to dst.
+func (idx *Index) collect(dst []Entry, positions []int) []Entry {
+	for _, i := range positions {
+		dst = append(dst, idx.entries[i])
+	}
+	return dst
+}
+
+// sortNames sorts the place names for prefix searches once all entries have been added.
+func (idx *Index) sortNames() {
+	idx.foldedNames = make([]string, 0, len(idx.byFoldedName))
+	for name := range idx.byFoldedName {
+		idx.foldedNames = append(idx.foldedNames, name)
+	}
+	sort.Strings(idx.foldedNames)
+}
+
+func (idx *Index) placeNameKey(name string) string {
+	if idx.fold {
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+// postalCodeKey returns the key of code in byPostalCode. If the index folds, the key is the code
+// in upper case with all whitespace removed.
+func (idx *Index) postalCodeKey(code string) string {
+	if idx.fold {
+		return strings.ToUpper(strings.Join(strings.Fields(code), ""))
+	}
+	return code
+}
+
+// ByPostalCode returns all entries with the given postal code in input order.
+// Postal codes are not unique, as multiple places may share a code, so more than one entry may be returned.
+// If the index was built with NewIndexFold, the code is matched ignoring case and whitespace.
+// It returns nil if there is no entry with the given postal code.
+func (idx *Index) ByPostalCode(code string) []Entry {
+	return idx.collect(nil, idx.byPostalCode[idx.postalCodeKey(code)])
+}
+
+// ByPlaceName returns all entries with the given place name in input order.
+// Places with the same name in different administrative divisions are all returned.
+// If the index was built with NewIndexFold, the name is matched case-insensitively.
+// It returns nil if there is no entry with the given place name.
+func (idx *Index) ByPlaceName(name string) []Entry {
+	return idx.collect(nil, idx.byPlaceName[idx.placeNameKey(name)])
+}
+
+// ByPlaceNamePrefix returns all entries whose place name begins with prefix, e.g. for autocompletion.
+// The prefix is matched case-insensitively, regardless of how the index was built.
+// The entries are sorted alphabetically by place name, ignoring case, and in input order for equal names.
+//
+// The place names are sorted when the index is built, so the lookup is a binary search.
+func (idx *Index) ByPlaceNamePrefix(prefix string) []Entry {
+	var matches []Entry
+	for _, name := range idx.prefixNames(prefix) {
+		matches = idx.collect(matches, idx.byFoldedName[name])
+	}
+	return matches
+}
+
+// ByPlaceNamePrefixUnique is like ByPlaceNamePrefix, but returns only the first entry for each place name,
+// which deduplicates places of the same name in different administrative divisions.
+func (idx *Index) ByPlaceNamePrefixUnique(prefix string) []Entry {
+	var matches []Entry
+	for _, name := range idx.prefixNames(prefix) {
+		matches = append(matches, idx.entries[idx.byFoldedName[name][0]])
+	}
+	return matches
+}
+
+// prefixNames returns the sorted lower-case place names beginning with prefix.
+func (idx *Index) prefixNames(prefix string) []string {
+	prefix = strings.ToLower(prefix)
+	start := sort.SearchStrings(idx.foldedNames, prefix)
+	end := start
+	for end < len(idx.foldedNames) && strings.HasPrefix(idx.foldedNames[end], prefix) {
+		end++
+	}
+	return idx.foldedNames[start:end]
+}
+
+// Centroid returns the average coordinates of all entries with the given postal code, e.g. as a single
+// representative point for a postal code that spans multiple places. Entries without valid coordinates are skipped.
+// It reports ok=false if no entry with the postal code has coordinates.
+func (idx *Index) Centroid(code string) (lat, lon float64, ok bool) {
+	n := 0
+	for _, e := range idx.ByPostalCode(code) {
+		eLat, eLon, err := coordinates(e)
+		if err != nil {
+			continue
+		}
+		lat += eLat
+		lon += eLon
+		n++
+	}
+	if n == 0 {
+		return 0, 0, false
+	}
+	return lat / float64(n), lon / float64(n), true
+}
+
+// AdminLevel is one level of the administrative divisions a place belongs to.
+type AdminLevel struct {
+	// Level is the level of the division, from 1 for the largest, e.g. a state, to 3 for the smallest.
+	Level int
+	// Name is the name of the division, e.g. "Rheinland-Pfalz".
+	Name string
+	// Code is the code of the division, e.g. "RP".
+	Code string
+}
+
+// adminFields holds the name and code fields of each administrative level, from level 1 to 3.
+var adminFields = [...][2]Field{{AdminName1, AdminCode1}, {AdminName2, AdminCode2}, {AdminName3, AdminCode3}}
+
+// AdminHierarchy returns the administrative divisions of the given postal code, from the largest to the smallest,
+// e.g. for breadcrumbs. Levels without name and code are omitted. If the entries with the postal code belong to
+// different divisions, the hierarchy shared by most of them is returned, and the first of those in input order
+// in case of a tie. It reports ok=false if there is no entry with the postal code.
+func (idx *Index) AdminHierarchy(code string) (_ []AdminLevel, ok bool) {
+	entries := idx.ByPostalCode(code)
+	if len(entries) == 0 {
+		return nil, false
+	}
+
+	hierarchies := make([][len(adminFields)][2]string, len(entries))
+	counts := make(map[[len(adminFields)][2]string]int)
+	for i, e := range entries {
+		for j, fs := range adminFields {
+			hierarchies[i][j] = [2]string{e[fs[0]], e[fs[1]]}
+		}
+		counts[hierarchies[i]]++
+	}
+	best := hierarchies[0]
+	for _, h := range hierarchies[1:] {
+		if counts[h] > counts[best] {
+			best = h
+		}
+	}
+
+	levels := make([]AdminLevel, 0, len(best))
+	for i, nc := range best {
+		if nc[0] == "" && nc[1] == "" {
+			continue
+		}
+		levels = append(levels, AdminLevel{Level: i + 1, Name: nc[0], Code: nc[1]})
+	}
+	return levels, true
+}
+
+// Nearest returns the entry closest to the given coordinates along with its distance in meters.
+// Entries without valid coordinates are skipped. It reports ok=false if no entry has coordinates.
+//
+// Nearest scans all entries, so each query takes O(n) time for n entries.
+func (idx *Index) Nearest(lat, lon float64) (_ Entry, meters float64, ok bool) {
+	var nearest Entry
+	for _, e := range idx.entries {
+		eLat, eLon, err := coordinates(e)
+		if err != nil {
+			continue
+		}
+		d := DistanceLatLon(lat, lon, eLat, eLon)
+		if !ok || d < meters {
+			nearest, meters, ok = e, d, true
+		}
+	}
+	return nearest, meters, ok
+}
+
+// InBoundingBox returns all entries whose coordinates fall within the given box in input order.
+// Entries without valid coordinates are skipped. The bounds are inclusive.
+//
+// If minLon is greater than maxLon, the box is taken to cross the antimeridian,
+// i.e. it spans from minLon eastwards to 180 and from -180 eastwards to maxLon.
+func (idx *Index) InBoundingBox(minLat, minLon, maxLat, maxLon float64) []Entry {
+	var in []Entry
+	for _, e := range idx.entries {
+		lat, lon, err := coordinates(e)
+		if err != nil {
+			continue
+		}
+		if lat < minLat || lat > maxLat {
+			continue
+		}
+		if minLon <= maxLon {
+			if lon < minLon || lon > maxLon {
+				continue
+			}
+		} else if lon < minLon && lon > maxLon {
+			continue
+		}
+		in = append(in, e)
+	}
+	return in
+}
@@ -0,0 +1,226 @@
+package geozip_test
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ngrash/geozip"
+)
+
+var indexEntries = []geozip.Entry{
+	{"DE", "54668", "Ferschweiler", "Rheinland-Pfalz", "RP", "", "00", "Eifelkreis Bitburg-Prüm", "07232", "49.8667", "6.4", "4"},
+	{"DE", "56479", "Neustadt (Westerwald)", "Rheinland-Pfalz", "RP", "", "00", "Westerwaldkreis", "07143", "50.6333", "8.0333", ""},
+	{"DE", "56479", "Rehe", "Rheinland-Pfalz", "RP", "", "00", "Westerwaldkreis", "07143", "50.6333", "8.0667", "4"},
+}
+
+func TestIndex_ByPostalCode(t *testing.T) {
+	idx := geozip.NewIndex(indexEntries)
+
+	entries := idx.ByPostalCode("56479")
+	if got, want := len(entries), 2; got != want {
+		t.Fatalf("len(entries) = %v, want %v", got, want)
+	}
+	if got, want := entries[0][geozip.PlaceName], "Neustadt (Westerwald)"; got != want {
+		t.Errorf("entries[0]: PlaceName = %v, want %v", got, want)
+	}
+	if got, want := entries[1][geozip.PlaceName], "Rehe"; got != want {
+		t.Errorf("entries[1]: PlaceName = %v, want %v", got, want)
+	}
+
+	if entries := idx.ByPostalCode("00000"); entries != nil {
+		t.Errorf("ByPostalCode(%q) = %v, want nil", "00000", entries)
+	}
+}
+
+func TestIndex_ByPlaceName(t *testing.T) {
+	idx := geozip.NewIndex(indexEntries)
+
+	entries := idx.ByPlaceName("Neustadt (Westerwald)")
+	if got, want := len(entries), 1; got != want {
+		t.Fatalf("len(entries) = %v, want %v", got, want)
+	}
+	if got, want := entries[0][geozip.PostalCode], "56479"; got != want {
+		t.Errorf("entries[0]: PostalCode = %v, want %v", got, want)
+	}
+
+	if entries := idx.ByPlaceName("neustadt (westerwald)"); entries != nil {
+		t.Errorf("ByPlaceName(%q) = %v, want nil", "neustadt (westerwald)", entries)
+	}
+}
+
+func TestIndexFold_ByPlaceName(t *testing.T) {
+	idx := geozip.NewIndexFold(indexEntries)
+
+	for _, name := range []string{"Neustadt (Westerwald)", "neustadt (westerwald)", "NEUSTADT (WESTERWALD)"} {
+		entries := idx.ByPlaceName(name)
+		if got, want := len(entries), 1; got != want {
+			t.Errorf("len(ByPlaceName(%q)) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestIndexFold_ByPostalCode(t *testing.T) {
+	entries := []geozip.Entry{
+		{"GB", "EC1A 1BB", "London"},
+		{"NL", "1012 AB", "Amsterdam"},
+	}
+	idx := geozip.NewIndexFold(entries)
+
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"EC1A 1BB", "London"},
+		{"ec1a 1bb", "London"},
+		{"EC1A1BB", "London"},
+		{" ec1a  1bb\t", "London"},
+		{"1012ab", "Amsterdam"},
+	}
+	for _, tt := range tests {
+		got := idx.ByPostalCode(tt.code)
+		if len(got) != 1 || got[0][geozip.PlaceName] != tt.want {
+			t.Errorf("ByPostalCode(%q) = %v, want %s", tt.code, got, tt.want)
+		}
+	}
+
+	if got := geozip.NewIndex(entries).ByPostalCode("ec1a 1bb"); got != nil {
+		t.Errorf("NewIndex: ByPostalCode(%q) = %v, want nil", "ec1a 1bb", got)
+	}
+}
+
+func TestIndex_Centroid(t *testing.T) {
+	idx := geozip.NewIndex(append(indexEntries, geozip.Entry{geozip.PostalCode: "56479", geozip.PlaceName: "Unknown"}))
+
+	lat, lon, ok := idx.Centroid("56479")
+	if !ok {
+		t.Fatal("ok = false, want true")
+	}
+	if got, want := lat, 50.6333; math.Abs(got-want) > 1e-9 {
+		t.Errorf("lat = %v, want %v", got, want)
+	}
+	if got, want := lon, 8.05; math.Abs(got-want) > 1e-9 {
+		t.Errorf("lon = %v, want %v", got, want)
+	}
+
+	if _, _, ok := idx.Centroid("99999"); ok {
+		t.Error("unknown postal code: ok = true, want false")
+	}
+}
+
+func TestIndex_Nearest(t *testing.T) {
+	idx := geozip.NewIndex(indexEntries)
+
+	e, meters, ok := idx.Nearest(50.63, 8.07)
+	if !ok {
+		t.Fatal("ok = false, want true")
+	}
+	if got, want := e[geozip.PlaceName], "Rehe"; got != want {
+		t.Errorf("PlaceName = %v, want %v", got, want)
+	}
+	if meters <= 0 || meters > 1000 {
+		t.Errorf("meters = %v, want between 0 and 1000", meters)
+	}
+}
+
+func TestIndex_Nearest_NoCoordinates(t *testing.T) {
+	idx := geozip.NewIndex([]geozip.Entry{{geozip.PostalCode: "12345"}})
+
+	if _, _, ok := idx.Nearest(0, 0); ok {
+		t.Error("ok = true, want false")
+	}
+}
+
+func TestIndex_InBoundingBox(t *testing.T) {
+	idx := geozip.NewIndex(indexEntries)
+
+	entries := idx.InBoundingBox(50, 8, 51, 8.05)
+	if got, want := len(entries), 1; got != want {
+		t.Fatalf("len(entries) = %v, want %v", got, want)
+	}
+	if got, want := entries[0][geozip.PlaceName], "Neustadt (Westerwald)"; got != want {
+		t.Errorf("PlaceName = %v, want %v", got, want)
+	}
+}
+
+func TestIndex_InBoundingBox_Antimeridian(t *testing.T) {
+	idx := geozip.NewIndex([]geozip.Entry{
+		{geozip.PlaceName: "Fiji", geozip.Latitude: "-17.7", geozip.Longitude: "178.1"},
+		{geozip.PlaceName: "Samoa", geozip.Latitude: "-13.8", geozip.Longitude: "-171.8"},
+		{geozip.PlaceName: "Greenwich", geozip.Latitude: "51.5", geozip.Longitude: "0"},
+		{geozip.PlaceName: "Nowhere"},
+	})
+
+	entries := idx.InBoundingBox(-20, 170, 0, -170)
+	if got, want := len(entries), 2; got != want {
+		t.Fatalf("len(entries) = %v, want %v", got, want)
+	}
+	if got, want := entries[0][geozip.PlaceName], "Fiji"; got != want {
+		t.Errorf("entries[0]: PlaceName = %v, want %v", got, want)
+	}
+	if got, want := entries[1][geozip.PlaceName], "Samoa"; got != want {
+		t.Errorf("entries[1]: PlaceName = %v, want %v", got, want)
+	}
+}
+
+func TestIndex_ByPlaceNamePrefix(t *testing.T) {
+	idx := geozip.NewIndex([]geozip.Entry{
+		{geozip.PostalCode: "1", geozip.PlaceName: "Neustadt (Wied)"},
+		{geozip.PostalCode: "2", geozip.PlaceName: "Berlin"},
+		{geozip.PostalCode: "3", geozip.PlaceName: "neuss"},
+		{geozip.PostalCode: "4", geozip.PlaceName: "Neustadt (Wied)"},
+		{geozip.PostalCode: "5", geozip.PlaceName: "Neu Wulmstorf"},
+		{geozip.PostalCode: "6", geozip.PlaceName: "Nettetal"},
+	})
+
+	var codes []string
+	for _, e := range idx.ByPlaceNamePrefix("NEU") {
+		codes = append(codes, e[geozip.PostalCode])
+	}
+	if got, want := strings.Join(codes, ","), "5,3,1,4"; got != want {
+		t.Errorf("ByPlaceNamePrefix(NEU) postal codes = %v, want %v", got, want)
+	}
+
+	codes = nil
+	for _, e := range idx.ByPlaceNamePrefixUnique("neu") {
+		codes = append(codes, e[geozip.PostalCode])
+	}
+	if got, want := strings.Join(codes, ","), "5,3,1"; got != want {
+		t.Errorf("ByPlaceNamePrefixUnique(neu) postal codes = %v, want %v", got, want)
+	}
+
+	if entries := idx.ByPlaceNamePrefix("x"); entries != nil {
+		t.Errorf("ByPlaceNamePrefix(x) = %v, want nil", entries)
+	}
+	if got, want := len(idx.ByPlaceNamePrefix("")), 6; got != want {
+		t.Errorf("len(ByPlaceNamePrefix(\"\")) = %v, want %v", got, want)
+	}
+}
+
+func TestIndex_AdminHierarchy(t *testing.T) {
+	rp := geozip.Entry{geozip.PostalCode: "56479", geozip.AdminName1: "Rheinland-Pfalz", geozip.AdminCode1: "RP",
+		geozip.AdminName2: "", geozip.AdminCode2: "00", geozip.AdminName3: "Westerwaldkreis", geozip.AdminCode3: "07143"}
+	he := geozip.Entry{geozip.PostalCode: "56479", geozip.AdminName1: "Hessen", geozip.AdminCode1: "HE"}
+	idx := geozip.NewIndex([]geozip.Entry{he, rp, rp, he, {geozip.PostalCode: "99999"}})
+
+	levels, ok := idx.AdminHierarchy("56479")
+	if !ok {
+		t.Fatal("ok = false, want true")
+	}
+	want := []geozip.AdminLevel{{1, "Hessen", "HE"}}
+	if !slices.Equal(levels, want) {
+		t.Errorf("tie: levels = %v, want %v", levels, want)
+	}
+
+	idx = geozip.NewIndex([]geozip.Entry{he, rp, rp})
+	levels, _ = idx.AdminHierarchy("56479")
+	want = []geozip.AdminLevel{{1, "Rheinland-Pfalz", "RP"}, {2, "", "00"}, {3, "Westerwaldkreis", "07143"}}
+	if !slices.Equal(levels, want) {
+		t.Errorf("majority: levels = %v, want %v", levels, want)
+	}
+
+	if _, ok := idx.AdminHierarchy("00000"); ok {
+		t.Error("unknown postal code: ok = true, want false")
+	}
+}
@@ -0,0 +1,52 @@
+package geozip
+
+import "net/http"
+
+// Option configures a Client created by New.
+type Option func(*Client)
+
+// New returns a Client configured by opts, which are applied in order.
+// Without options, it is equivalent to the zero value Client.
+//
+// Options are an alternative to setting the fields of a Client directly:
+//
+//	client := geozip.New(
+//	    geozip.WithBaseURL("https://mirror.example.com/zip"),
+//	    geozip.WithUserAgent("my-app/1.0"),
+//	)
+func New(opts ...Option) *Client {
+	c := &Client{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithHTTPClient sets the http.Client used for making requests instead of the package-level HTTPClient.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.HTTPClient = hc
+	}
+}
+
+// WithBaseURL sets the URL of the directory holding the zip archives, e.g. of an internal mirror,
+// instead of DefaultBaseURL.
+func WithBaseURL(url string) Option {
+	return func(c *Client) {
+		c.BaseURL = url
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.UserAgent = ua
+	}
+}
+
+// WithRetryPolicy sets how transiently failed requests are retried. By default, requests are not retried.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) {
+		c.RetryPolicy = p
+	}
+}
@@ -0,0 +1,54 @@
+package geozip_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ngrash/geozip"
+)
+
+func TestNew(t *testing.T) {
+	data := zipArchive(t, map[string]string{"DE.txt": "DE\t54668\tFerschweiler\n"})
+	serve := serveBytes(data, "etag")
+	attempts := 0
+	hc := &http.Client{
+		Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			attempts++
+			if got, want := r.URL.String(), "https://mirror.example.com/zip/DE.zip"; got != want {
+				t.Errorf("client requested %q, want %q", got, want)
+			}
+			if got, want := r.Header.Get("User-Agent"), "my-app/1.0"; got != want {
+				t.Errorf("User-Agent = %q, want %q", got, want)
+			}
+			if attempts == 1 {
+				return &http.Response{StatusCode: http.StatusServiceUnavailable, Status: "503 Service Unavailable"}, nil
+			}
+			return serve(r)
+		}),
+	}
+
+	client := geozip.New(
+		geozip.WithHTTPClient(hc),
+		geozip.WithBaseURL("https://mirror.example.com/zip/"),
+		geozip.WithUserAgent("my-app/1.0"),
+		geozip.WithRetryPolicy(geozip.RetryPolicy{MaxRetries: 1, BaseDelay: time.Millisecond}),
+	)
+	entries, _, _, err := client.FetchCountry("DE", "")
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if got, want := len(entries), 1; got != want {
+		t.Errorf("len(entries) = %v, want %v", got, want)
+	}
+	if got, want := attempts, 2; got != want {
+		t.Errorf("%d attempts, want %d", got, want)
+	}
+}
+
+func TestNew_Defaults(t *testing.T) {
+	c := geozip.New()
+	if c.HTTPClient != nil || c.BaseURL != "" || c.UserAgent != "" || c.RetryPolicy != (geozip.RetryPolicy{}) {
+		t.Errorf("New() = %+v, want zero value", c)
+	}
+}
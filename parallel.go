@@ -0,0 +1,104 @@
+package geozip
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// ParseParallel parses tab-separated postal code data like ParseStream, but splits data into chunks at line
+// boundaries and parses them on multiple goroutines. The entries are returned in input order.
+// As with ParseStream, all rows must have as many fields as the first row, across chunks.
+// This speeds up parsing large inputs, such as allCountries.txt, which is otherwise bound to a single CPU core.
+//
+// At most workers chunks are parsed concurrently. If workers is zero or negative, runtime.GOMAXPROCS(0) is used.
+//
+// Splitting at line boundaries is only correct if no field contains a line break, which holds for GeoNames data.
+func ParseParallel(data []byte, workers int) ([]Entry, error) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	chunks := splitLines(data, workers)
+	// Each chunk has its own reader, so the number of fields of the first row is passed to all of them
+	// to require the same number of fields across chunks, as ParseStream does.
+	cfg := parseConfig{fieldsPerRecord: firstRecordFields(data)}
+
+	results := make([][]Entry, len(chunks))
+	errs := make([]error, len(chunks))
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(i int, chunk []byte) {
+			defer wg.Done()
+			results[i], errs[i] = parseCSV(bytes.NewReader(chunk), cfg)
+			if errs[i] != nil {
+				errs[i] = withLineContext(errs[i], openBytes(chunk))
+			}
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	line := 0
+	for i, err := range errs {
+		if err != nil {
+			return nil, offsetLines(err, line)
+		}
+		line += bytes.Count(chunks[i], []byte{'\n'})
+	}
+
+	n := 0
+	for _, es := range results {
+		n += len(es)
+	}
+	entries := make([]Entry, 0, n)
+	for _, es := range results {
+		entries = append(entries, es...)
+	}
+	return entries, nil
+}
+
+// firstRecordFields returns the number of fields of the first row of data, or zero if it cannot be read,
+// in which case parsing the first chunk reports the error.
+func firstRecordFields(data []byte) int {
+	columns, err := readRecord(newCSVReader(bytes.NewReader(data)))
+	if err != nil {
+		return 0
+	}
+	return len(columns)
+}
+
+// splitLines splits data into at most n chunks of roughly equal size, each ending with a complete line.
+func splitLines(data []byte, n int) [][]byte {
+	var chunks [][]byte
+	size := len(data)/n + 1
+	for len(data) > 0 {
+		end := size
+		if end >= len(data) {
+			end = len(data)
+		} else if i := bytes.IndexByte(data[end:], '\n'); i >= 0 {
+			end += i + 1
+		} else {
+			end = len(data)
+		}
+		chunks = append(chunks, data[:end])
+		data = data[end:]
+	}
+	return chunks
+}
+
+// offsetLines adds offset to the line numbers of parse errors in err, which refer to a chunk of the input,
+// so that they refer to the whole input.
+func offsetLines(err error, offset int) error {
+	var perr *ParseError
+	if errors.As(err, &perr) {
+		perr.Line += offset
+	}
+	var cerr *csv.ParseError
+	if errors.As(err, &cerr) {
+		cerr.StartLine += offset
+		cerr.Line += offset
+	}
+	return err
+}
@@ -0,0 +1,121 @@
+package geozip_test
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ngrash/geozip"
+)
+
+// readTestData returns the decompressed DE.txt from the test data.
+func readTestData(tb testing.TB) []byte {
+	tb.Helper()
+	r, err := zip.OpenReader("test_data/DE.zip")
+	if err != nil {
+		tb.Fatal("open test data", err)
+	}
+	defer r.Close()
+	f, err := r.Open("DE.txt")
+	if err != nil {
+		tb.Fatal("open DE.txt", err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		tb.Fatal("read DE.txt", err)
+	}
+	return data
+}
+
+func TestParseParallel(t *testing.T) {
+	data := readTestData(t)
+	want, err := geozip.ParseStrict(data)
+	if err != nil {
+		t.Fatal("parse test data", err)
+	}
+
+	for _, workers := range []int{0, 1, 3, 8, 100000} {
+		entries, err := geozip.ParseParallel(data, workers)
+		if err != nil {
+			t.Fatalf("workers = %d: err = %v, want nil", workers, err)
+		}
+		if got, want := len(entries), len(want); got != want {
+			t.Fatalf("workers = %d: len(entries) = %v, want %v", workers, got, want)
+		}
+		for i := range entries {
+			if entries[i] != want[i] {
+				t.Fatalf("workers = %d: entries[%d] = %v, want %v", workers, i, entries[i], want[i])
+			}
+		}
+	}
+}
+
+func TestParseParallel_Empty(t *testing.T) {
+	entries, err := geozip.ParseParallel(nil, 4)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("len(entries) = %v, want 0", len(entries))
+	}
+}
+
+func TestParseParallel_ErrorLine(t *testing.T) {
+	data := strings.Repeat("DE\t54668\tFerschweiler\n", 99) + "DE\t56479\tNeu\"stadt\n"
+
+	_, err := geozip.ParseParallel([]byte(data), 4)
+	var perr *geozip.ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("err = %v, want *geozip.ParseError", err)
+	}
+	if got, want := perr.Line, 100; got != want {
+		t.Errorf("Line = %v, want %v", got, want)
+	}
+	if got, want := perr.Snippet, "DE\t56479\tNeu\"stadt"; got != want {
+		t.Errorf("Snippet = %q, want %q", got, want)
+	}
+	if !strings.Contains(err.Error(), "line 100") {
+		t.Errorf("err = %q, want it to mention line 100", err)
+	}
+}
+
+func TestParseParallel_FieldCount(t *testing.T) {
+	// The rows end up in different chunks, whose readers must agree on the number of fields.
+	data := "DE\t54668\tFerschweiler\tRheinland-Pfalz\tRP\t\t00\tEifelkreis Bitburg-Prüm\t07232\t49.8667\t6.4\t4\n" +
+		"DE\t54636\tBitburg\n"
+
+	if err := geozip.ParseStream(strings.NewReader(data), func(geozip.Entry) error { return nil }); err == nil {
+		t.Fatal("ParseStream: err = nil, want error for rows with different numbers of fields")
+	}
+	_, err := geozip.ParseParallel([]byte(data), 2)
+	if !errors.Is(err, csv.ErrFieldCount) {
+		t.Fatalf("err = %v, want %v", err, csv.ErrFieldCount)
+	}
+	if !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("err = %q, want it to mention line 2", err)
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	data := readTestData(b)
+	b.Run("Serial", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			if _, err := geozip.ParseStrict(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Parallel", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			if _, err := geozip.ParseParallel(data, 0); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
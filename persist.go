@@ -0,0 +1,46 @@
+package geozip
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// SaveEntries writes entries to w in the tab-separated format used by GeoNames, so they can be reloaded
+// with LoadEntries later, e.g. for offline operation. It is equivalent to WriteTSV.
+func SaveEntries(w io.Writer, entries []Entry) error {
+	return WriteTSV(w, entries)
+}
+
+// WriteTSV writes entries to w in the tab-separated format used by GeoNames, one row per line.
+// It is the inverse of parsing, so entries written by WriteTSV parse back to the same entries.
+// Fields containing tabs, quotes or line breaks, which do not occur in GeoNames data, are quoted,
+// so all fields round-trip exactly.
+func WriteTSV(w io.Writer, entries []Entry) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = '\t'
+	for _, e := range entries {
+		if err := writer.Write(e[:]); err != nil {
+			return fmt.Errorf("write entry: %w", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// LoadEntries reads entries written by SaveEntries from r.
+func LoadEntries(r io.Reader) ([]Entry, error) {
+	reader := newCSVReader(r)
+	reader.FieldsPerRecord = numFields
+	es := make([]Entry, 0)
+	for {
+		columns, err := readRecord(reader)
+		if err == io.EOF {
+			return es, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		es = append(es, newEntry(columns))
+	}
+}
@@ -0,0 +1,91 @@
+package geozip_test
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/ngrash/geozip"
+)
+
+func TestSaveEntries_LoadEntries(t *testing.T) {
+	entries := []geozip.Entry{
+		{"DE", "54668", "Ferschweiler", "Rheinland-Pfalz", "RP", "", "00", "Eifelkreis Bitburg-Prüm", "07232", "49.8667", "6.4", "4"},
+		{"XX", "00000", "Place\twith\ttabs", `"Quoted"`, "a \"b\" c", "line\nbreak", " leading space", "", "", "", "", ""},
+		{},
+	}
+
+	var buf bytes.Buffer
+	if err := geozip.SaveEntries(&buf, entries); err != nil {
+		t.Fatalf("SaveEntries: err = %v, want nil", err)
+	}
+	loaded, err := geozip.LoadEntries(&buf)
+	if err != nil {
+		t.Fatalf("LoadEntries: err = %v, want nil", err)
+	}
+
+	if got, want := len(loaded), len(entries); got != want {
+		t.Fatalf("len(loaded) = %v, want %v", got, want)
+	}
+	for i := range entries {
+		if got, want := loaded[i], entries[i]; got != want {
+			t.Errorf("loaded[%d] = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestSaveEntries_LoadEntries_FetchedData(t *testing.T) {
+	file, err := os.Open("test_data/DE.zip")
+	if err != nil {
+		t.Fatal("open test data", err)
+	}
+	defer file.Close()
+	entries, err := geozip.ParseReader(file, "DE")
+	if err != nil {
+		t.Fatal("parse test data", err)
+	}
+
+	var buf bytes.Buffer
+	if err := geozip.SaveEntries(&buf, entries); err != nil {
+		t.Fatalf("SaveEntries: err = %v, want nil", err)
+	}
+	loaded, err := geozip.LoadEntries(&buf)
+	if err != nil {
+		t.Fatalf("LoadEntries: err = %v, want nil", err)
+	}
+
+	if got, want := len(loaded), len(entries); got != want {
+		t.Fatalf("len(loaded) = %v, want %v", got, want)
+	}
+	for i := range entries {
+		if loaded[i] != entries[i] {
+			t.Fatalf("loaded[%d] = %q, want %q", i, loaded[i], entries[i])
+		}
+	}
+}
+
+func TestWriteTSV(t *testing.T) {
+	want := readTestData(t)
+	entries, err := geozip.ParseStrict(want)
+	if err != nil {
+		t.Fatal("parse test data", err)
+	}
+
+	var buf bytes.Buffer
+	if err := geozip.WriteTSV(&buf, entries); err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Error("WriteTSV() output differs from the original GeoNames file")
+	}
+
+	zipped := zipArchive(t, map[string]string{"DE.txt": buf.String()})
+	parsed, err := geozip.ParseReader(bytes.NewReader(zipped), "DE")
+	if err != nil {
+		t.Fatalf("ParseReader: err = %v, want nil", err)
+	}
+	if !slices.Equal(parsed, entries) {
+		t.Error("entries parsed from WriteTSV() output differ from the original entries")
+	}
+}
@@ -0,0 +1,112 @@
+package geozip
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// NormalizePlaceName trims surrounding whitespace from a place name and collapses internal runs of whitespace
+// into single spaces, e.g. "Neustadt  (Westerwald) " becomes "Neustadt (Westerwald)". Apply it to both fetched
+// entries and user queries for exact matching. Entries are never modified by this package.
+func NormalizePlaceName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// PlaceNameSearchKey returns a key for matching place names loosely, e.g. in search: the name is normalized like
+// NormalizePlaceName, converted to lower case, and Latin letters with diacritics are folded to their base letters,
+// so that "Prüm", "PRUM" and " prum" share the key "prum". Ligatures and special letters are spelled out,
+// e.g. "ß" becomes "ss".
+//
+// The key is intended for comparisons only and should not be displayed.
+func PlaceNameSearchKey(name string) string {
+	name = strings.ToLower(NormalizePlaceName(name))
+	var b strings.Builder
+	b.Grow(len(name))
+	for _, r := range name {
+		if folded, ok := diacriticFolds[r]; ok {
+			b.WriteString(folded)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// diacriticFolds maps lower-case Latin letters with diacritics to their base letters.
+var diacriticFolds = func() map[rune]string {
+	folds := map[string]string{
+		"a":  "àáâãäåāăą",
+		"c":  "çćĉċč",
+		"d":  "ďđð",
+		"e":  "èéêëēĕėęě",
+		"g":  "ĝğġģ",
+		"h":  "ĥħ",
+		"i":  "ìíîïĩīĭįı",
+		"j":  "ĵ",
+		"k":  "ķ",
+		"l":  "ĺļľŀł",
+		"n":  "ñńņňŉ",
+		"o":  "òóôõöøōŏő",
+		"r":  "ŕŗř",
+		"s":  "śŝşšș",
+		"t":  "ţťŧț",
+		"u":  "ùúûüũūŭůűų",
+		"w":  "ŵ",
+		"y":  "ýÿŷ",
+		"z":  "źżž",
+		"ae": "æ",
+		"oe": "œ",
+		"ss": "ß",
+		"th": "þ",
+	}
+	m := make(map[rune]string)
+	for base, letters := range folds {
+		for _, r := range letters {
+			m[r] = base
+		}
+	}
+	return m
+}()
+
+// SortByPlaceName sorts entries in place by place name, comparing the names byte by byte.
+// Entries with equal place names keep their input order. For display, see SortByPlaceNameLocale.
+func SortByPlaceName(entries []Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i][PlaceName] < entries[j][PlaceName]
+	})
+}
+
+// SortByPlaceNameLocale sorts entries in place by place name according to the collation of the language tag,
+// e.g. for display. Unlike SortByPlaceName, letters with diacritics sort near their base letters, so that
+// for language.German "Ärzen" sorts between "Aachen" and "Berlin" rather than after "Zwickau".
+// Entries with place names that collate equally keep their input order.
+//
+// The collation key of each place name is computed once upfront, so sorting large datasets stays fast.
+func SortByPlaceNameLocale(entries []Entry, tag language.Tag) {
+	c := collate.New(tag)
+	var buf collate.Buffer
+	keys := make([][]byte, len(entries))
+	for i, e := range entries {
+		keys[i] = c.KeyFromString(&buf, e[PlaceName])
+	}
+	sort.Stable(byCollationKey{entries: entries, keys: keys})
+}
+
+// byCollationKey sorts entries by the collation keys of their place names.
+type byCollationKey struct {
+	entries []Entry
+	keys    [][]byte
+}
+
+func (s byCollationKey) Len() int { return len(s.entries) }
+
+func (s byCollationKey) Less(i, j int) bool { return bytes.Compare(s.keys[i], s.keys[j]) < 0 }
+
+func (s byCollationKey) Swap(i, j int) {
+	s.entries[i], s.entries[j] = s.entries[j], s.entries[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
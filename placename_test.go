@@ -0,0 +1,89 @@
+package geozip_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ngrash/geozip"
+	"golang.org/x/text/language"
+)
+
+func TestNormalizePlaceName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Ferschweiler", "Ferschweiler"},
+		{" Neustadt  (Westerwald)\t", "Neustadt (Westerwald)"},
+		{"Bitburg- Prüm", "Bitburg- Prüm"},
+		{"   ", ""},
+	}
+	for _, tt := range tests {
+		if got := geozip.NormalizePlaceName(tt.name); got != tt.want {
+			t.Errorf("NormalizePlaceName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPlaceNameSearchKey(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Prüm", "prum"},
+		{" PRÜM ", "prum"},
+		{"Großenhain", "grossenhain"},
+		{"Český  Krumlov", "cesky krumlov"},
+		{"Łódź", "lodz"},
+	}
+	for _, tt := range tests {
+		if got := geozip.PlaceNameSearchKey(tt.name); got != tt.want {
+			t.Errorf("PlaceNameSearchKey(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// placeNamesOf returns the place names of entries in order.
+func placeNamesOf(entries []geozip.Entry) []string {
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e[geozip.PlaceName]
+	}
+	return names
+}
+
+func TestSortByPlaceName(t *testing.T) {
+	entries := []geozip.Entry{
+		{geozip.PlaceName: "Zwickau"},
+		{geozip.PlaceName: "Ärzen"},
+		{geozip.PlaceName: "Berlin", geozip.PostalCode: "10115"},
+		{geozip.PlaceName: "Aachen"},
+		{geozip.PlaceName: "Berlin", geozip.PostalCode: "10117"},
+	}
+
+	tests := []struct {
+		name string
+		sort func([]geozip.Entry)
+		want []string
+	}{
+		{"bytes", geozip.SortByPlaceName, []string{"Aachen", "Berlin", "Berlin", "Zwickau", "Ärzen"}},
+		{"German", func(es []geozip.Entry) { geozip.SortByPlaceNameLocale(es, language.German) },
+			[]string{"Aachen", "Ärzen", "Berlin", "Berlin", "Zwickau"}},
+		{"Swedish", func(es []geozip.Entry) { geozip.SortByPlaceNameLocale(es, language.Swedish) },
+			[]string{"Aachen", "Berlin", "Berlin", "Zwickau", "Ärzen"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorted := slices.Clone(entries)
+			tt.sort(sorted)
+			if got := placeNamesOf(sorted); !slices.Equal(got, tt.want) {
+				t.Errorf("sorted = %v, want %v", got, tt.want)
+			}
+			// Equal names keep their input order.
+			i := slices.IndexFunc(sorted, func(e geozip.Entry) bool { return e[geozip.PlaceName] == "Berlin" })
+			if sorted[i][geozip.PostalCode] != "10115" || sorted[i+1][geozip.PostalCode] != "10117" {
+				t.Errorf("Berlin entries = %v, %v, want input order", sorted[i], sorted[i+1])
+			}
+		})
+	}
+}
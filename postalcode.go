@@ -0,0 +1,46 @@
+package geozip
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	fiveDigits = regexp.MustCompile(`^[0-9]{5}$`)
+	// gbPostcode matches a British postcode, either complete or just the outward code
+	// that the GeoNames GB archive is limited to.
+	gbPostcode = regexp.MustCompile(`^[A-Z]{1,2}[0-9][A-Z0-9]?( [0-9][A-Z]{2})?$`)
+)
+
+// NormalizePostalCode canonicalizes a postal code, e.g. from user input, for matching it against fetched entries.
+// Surrounding whitespace is trimmed. For countries with well-known formats, the shape is validated as well:
+//
+//	DE, US: five digits, e.g. "54668"
+//	GB: upper-case alphanumeric with a single space before the inward code, e.g. "EC1A 1BB" or just "EC1A"
+//
+// Postal codes of other countries are only trimmed.
+func NormalizePostalCode(cc, code string) (string, error) {
+	cc, err := normalizeCountryCode(cc)
+	if err != nil {
+		return "", err
+	}
+
+	code = strings.TrimSpace(code)
+	switch cc {
+	case "DE", "US":
+		if !fiveDigits.MatchString(code) {
+			return "", fmt.Errorf("postal code %q is not valid for %s, want five digits", code, cc)
+		}
+	case "GB":
+		code = strings.ToUpper(strings.Join(strings.Fields(code), ""))
+		if len(code) > 4 {
+			// The inward code always has three characters.
+			code = code[:len(code)-3] + " " + code[len(code)-3:]
+		}
+		if !gbPostcode.MatchString(code) {
+			return "", fmt.Errorf("postal code %q is not valid for %s", code, cc)
+		}
+	}
+	return code, nil
+}
@@ -0,0 +1,47 @@
+package geozip_test
+
+import (
+	"testing"
+
+	"github.com/ngrash/geozip"
+)
+
+func TestNormalizePostalCode(t *testing.T) {
+	tests := []struct {
+		cc, code string
+		want     string
+		wantErr  bool
+	}{
+		{cc: "DE", code: " 54668\t", want: "54668"},
+		{cc: "de", code: "54668", want: "54668"},
+		{cc: "DE", code: "5466", wantErr: true},
+		{cc: "DE", code: "5466A", wantErr: true},
+		{cc: "US", code: "90210", want: "90210"},
+		{cc: "US", code: "90210-1234", wantErr: true},
+		{cc: "GB", code: "ec1a 1bb", want: "EC1A 1BB"},
+		{cc: "GB", code: "EC1A1BB", want: "EC1A 1BB"},
+		{cc: "GB", code: " SW1A  2AA ", want: "SW1A 2AA"},
+		{cc: "GB", code: "M1 1AE", want: "M1 1AE"},
+		{cc: "GB", code: "ab10", want: "AB10"},
+		{cc: "GB", code: "12345", wantErr: true},
+		{cc: "NL", code: " 1012 AB ", want: "1012 AB"},
+		{cc: "XXX", code: "1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cc+"/"+tt.code, func(t *testing.T) {
+			got, err := geozip.NormalizePostalCode(tt.cc, tt.code)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("NormalizePostalCode() = %q, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v, want nil", err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizePostalCode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
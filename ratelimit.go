@@ -0,0 +1,92 @@
+package geozip
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limiter throttles outbound requests. Wait blocks until a request may be made or ctx is done.
+//
+// *rate.Limiter from golang.org/x/time/rate implements Limiter, as does the simple limiter
+// returned by NewRateLimiter.
+type Limiter interface {
+	Wait(ctx context.Context) error
+}
+
+// NewRateLimiter returns a token bucket Limiter allowing requestsPerSecond requests per second on average,
+// with bursts of up to burst requests. A burst of less than one is treated as one.
+// If requestsPerSecond is zero, negative or NaN, requests are not limited.
+func NewRateLimiter(requestsPerSecond float64, burst int) Limiter {
+	if !(requestsPerSecond > 0) {
+		return unlimited{}
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	// An interval of zero would divide by zero when refilling the bucket.
+	interval := max(time.Duration(float64(time.Second)/requestsPerSecond), 1)
+	return &rateLimiter{
+		interval: interval,
+		burst:    burst,
+		tokens:   float64(burst),
+		last:     time.Now(),
+		now:      time.Now,
+	}
+}
+
+// unlimited is a Limiter that never waits.
+type unlimited struct{}
+
+func (unlimited) Wait(ctx context.Context) error {
+	return nil
+}
+
+// rateLimiter is a simple token bucket.
+type rateLimiter struct {
+	interval time.Duration
+	burst    int
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	// now returns the current time, replaced in tests.
+	now func() time.Time
+}
+
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	for {
+		d := l.reserve()
+		if d == 0 {
+			return nil
+		}
+		timer := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes a token and returns zero, or returns how long to wait until a token is available.
+// The wait is rounded up, so that a token is available afterwards, and is never zero if no token was taken.
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+	if l.tokens > float64(l.burst) {
+		l.tokens = float64(l.burst)
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return max(time.Duration(math.Ceil((1-l.tokens)*float64(l.interval))), 1)
+}
@@ -0,0 +1,111 @@
+package geozip_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ngrash/geozip"
+)
+
+func TestNewRateLimiter(t *testing.T) {
+	l := geozip.NewRateLimiter(100, 2)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatalf("Wait: err = %v, want nil", err)
+		}
+	}
+	// Two requests pass immediately, the other two wait 10ms each.
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("4 requests took %v, want at least 15ms", elapsed)
+	}
+}
+
+func TestNewRateLimiter_Canceled(t *testing.T) {
+	l := geozip.NewRateLimiter(0.001, 1)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("first Wait: err = %v, want nil", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("second Wait: err = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestNewRateLimiter_Unlimited(t *testing.T) {
+	for _, rps := range []float64{0, -1, math.NaN()} {
+		l := geozip.NewRateLimiter(rps, 1)
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		for i := 0; i < 100; i++ {
+			if err := l.Wait(ctx); err != nil {
+				t.Errorf("NewRateLimiter(%v, 1): Wait %d: err = %v, want nil", rps, i, err)
+				break
+			}
+		}
+		cancel()
+	}
+}
+
+func TestNewRateLimiter_FractionalToken(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := geozip.NewRateLimiterClock(1, 1, func() time.Time { return now })
+	if d := geozip.Reserve(l); d != 0 {
+		t.Fatalf("first reserve = %v, want 0", d)
+	}
+
+	// The bucket is refilled in two steps to a nanosecond short of a token. Due to rounding errors,
+	// the remaining wait computes to just under a nanosecond, which must not be truncated to no wait at all.
+	now = now.Add(2 * time.Nanosecond)
+	if d := geozip.Reserve(l); d <= 0 {
+		t.Fatalf("reserve after 2ns = %v, want more than 0", d)
+	}
+	now = now.Add(time.Second - 3*time.Nanosecond)
+	d := geozip.Reserve(l)
+	if d <= 0 {
+		t.Fatalf("reserve with a fractional token = %v, want more than 0", d)
+	}
+	now = now.Add(d)
+	if d := geozip.Reserve(l); d != 0 {
+		t.Errorf("reserve after waiting = %v, want 0", d)
+	}
+	if d := geozip.Reserve(l); d <= 0 {
+		t.Errorf("reserve after taking the token = %v, want more than 0", d)
+	}
+}
+
+// limiterFunc adapts a function to the Limiter interface.
+type limiterFunc func(ctx context.Context) error
+
+func (fn limiterFunc) Wait(ctx context.Context) error {
+	return fn(ctx)
+}
+
+func TestClient_Limiter(t *testing.T) {
+	waits := 0
+	client := &geozip.Client{
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				if waits != 1 {
+					t.Errorf("request made after %d waits, want 1", waits)
+				}
+				return &http.Response{StatusCode: http.StatusNotModified}, nil
+			}),
+		},
+		Limiter: limiterFunc(func(ctx context.Context) error {
+			waits++
+			return nil
+		}),
+	}
+
+	if _, _, _, err := client.FetchCountry("DE", "etag"); err != nil {
+		t.Errorf("err = %v, want nil", err)
+	}
+}
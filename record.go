@@ -0,0 +1,108 @@
+package geozip
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Record is a postal code entry with named and typed fields.
+// Optional numeric fields are nil if they are blank in the entry.
+//
+// Unlike an Entry, which is an array and copied on assignment, copies of a Record share the values of its pointer
+// fields. Each Record returned by Entry.Record owns its values, but a copy made by assignment does not;
+// use Clone to get a copy that can be modified without affecting the original, e.g. in another goroutine.
+type Record struct {
+	CountryCode string
+	PostalCode  string
+	PlaceName   string
+	AdminName1  string
+	AdminCode1  string
+	AdminName2  string
+	AdminCode2  string
+	AdminName3  string
+	AdminCode3  string
+	Latitude    *float64
+	Longitude   *float64
+	Accuracy    *int
+}
+
+// Record converts e to a Record. It returns an error if a numeric field is not blank and cannot be parsed.
+func (e Entry) Record() (Record, error) {
+	r := Record{
+		CountryCode: e[CountryCode],
+		PostalCode:  e[PostalCode],
+		PlaceName:   e[PlaceName],
+		AdminName1:  e[AdminName1],
+		AdminCode1:  e[AdminCode1],
+		AdminName2:  e[AdminName2],
+		AdminCode2:  e[AdminCode2],
+		AdminName3:  e[AdminName3],
+		AdminCode3:  e[AdminCode3],
+	}
+
+	var err error
+	if r.Latitude, err = parseOptionalFloat(e[Latitude]); err != nil {
+		return Record{}, fmt.Errorf("parse latitude: %w", err)
+	}
+	if r.Longitude, err = parseOptionalFloat(e[Longitude]); err != nil {
+		return Record{}, fmt.Errorf("parse longitude: %w", err)
+	}
+	if e[Accuracy] != "" {
+		a, err := strconv.Atoi(e[Accuracy])
+		if err != nil {
+			return Record{}, fmt.Errorf("parse accuracy: %w", err)
+		}
+		r.Accuracy = &a
+	}
+
+	return r, nil
+}
+
+// Clone returns a deep copy of r, whose pointer fields point to copies of the values of r, if not nil.
+func (r Record) Clone() Record {
+	if r.Latitude != nil {
+		lat := *r.Latitude
+		r.Latitude = &lat
+	}
+	if r.Longitude != nil {
+		lon := *r.Longitude
+		r.Longitude = &lon
+	}
+	if r.Accuracy != nil {
+		a := *r.Accuracy
+		r.Accuracy = &a
+	}
+	return r
+}
+
+// Accuracy levels of coordinates as documented by GeoNames, on a scale from 1 to 6 where higher is more precise.
+// Levels in between are used as well; these are the ones with a documented meaning.
+const (
+	// AccuracyEstimated means that the coordinates are estimated.
+	AccuracyEstimated = 1
+	// AccuracyGeonameID means that the coordinates are taken from the GeoNames place (geonameid).
+	AccuracyGeonameID = 4
+	// AccuracyCentroid means that the coordinates are the centroid of addresses or of the postal code area's shape.
+	AccuracyCentroid = 6
+)
+
+// Accuracy returns the parsed Accuracy field of e, which can be compared to the Accuracy levels such
+// as AccuracyCentroid. It returns false if the field is blank or not an integer.
+func (e Entry) Accuracy() (int, bool) {
+	a, err := strconv.Atoi(e[Accuracy])
+	if err != nil {
+		return 0, false
+	}
+	return a, true
+}
+
+func parseOptionalFloat(s string) (*float64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
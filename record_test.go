@@ -0,0 +1,95 @@
+package geozip_test
+
+import (
+	"testing"
+
+	"github.com/ngrash/geozip"
+)
+
+func TestEntry_Record(t *testing.T) {
+	e := geozip.Entry{"DE", "54668", "Ferschweiler", "Rheinland-Pfalz", "RP", "", "00", "Eifelkreis Bitburg-Prüm", "07232", "49.8667", "6.4", "4"}
+
+	r, err := e.Record()
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if got, want := r.PostalCode, "54668"; got != want {
+		t.Errorf("PostalCode = %v, want %v", got, want)
+	}
+	if got, want := r.AdminName3, "Eifelkreis Bitburg-Prüm"; got != want {
+		t.Errorf("AdminName3 = %v, want %v", got, want)
+	}
+	if r.Latitude == nil || *r.Latitude != 49.8667 {
+		t.Errorf("Latitude = %v, want 49.8667", r.Latitude)
+	}
+	if r.Longitude == nil || *r.Longitude != 6.4 {
+		t.Errorf("Longitude = %v, want 6.4", r.Longitude)
+	}
+	if r.Accuracy == nil || *r.Accuracy != 4 {
+		t.Errorf("Accuracy = %v, want 4", r.Accuracy)
+	}
+}
+
+func TestEntry_Record_Blank(t *testing.T) {
+	e := geozip.Entry{geozip.PostalCode: "56479"}
+
+	r, err := e.Record()
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if r.Latitude != nil {
+		t.Errorf("Latitude = %v, want nil", *r.Latitude)
+	}
+	if r.Longitude != nil {
+		t.Errorf("Longitude = %v, want nil", *r.Longitude)
+	}
+	if r.Accuracy != nil {
+		t.Errorf("Accuracy = %v, want nil", *r.Accuracy)
+	}
+}
+
+func TestEntry_Record_Invalid(t *testing.T) {
+	e := geozip.Entry{geozip.Latitude: "north"}
+
+	if _, err := e.Record(); err == nil {
+		t.Error("err = nil, want error")
+	}
+}
+
+func TestEntry_Accuracy(t *testing.T) {
+	tests := []struct {
+		field  string
+		want   int
+		wantOK bool
+	}{
+		{"4", geozip.AccuracyGeonameID, true},
+		{"6", geozip.AccuracyCentroid, true},
+		{"", 0, false},
+		{"high", 0, false},
+	}
+	for _, tt := range tests {
+		e := geozip.Entry{geozip.Accuracy: tt.field}
+		got, ok := e.Accuracy()
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("Entry{Accuracy: %q}.Accuracy() = %v, %v, want %v, %v", tt.field, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestRecord_Clone(t *testing.T) {
+	r, err := geozip.Entry{geozip.PostalCode: "54668", geozip.Latitude: "49.8667", geozip.Longitude: "6.4", geozip.Accuracy: "4"}.Record()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := r.Clone()
+	*c.Latitude, *c.Longitude, *c.Accuracy = 0, 0, 0
+	c.PostalCode = "00000"
+	if *r.Latitude != 49.8667 || *r.Longitude != 6.4 || *r.Accuracy != 4 || r.PostalCode != "54668" {
+		t.Errorf("original changed to %+v after modifying clone", r)
+	}
+
+	if c := (geozip.Record{}).Clone(); c.Latitude != nil || c.Longitude != nil || c.Accuracy != nil {
+		t.Errorf("Clone of blank record = %+v, want nil pointers", c)
+	}
+}
@@ -0,0 +1,114 @@
+package geozip
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures how a Client retries requests that failed transiently.
+// Network errors and the status codes 429, 500, 502, 503 and 504 are retried.
+//
+// The zero value disables retries.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries after the initial attempt.
+	MaxRetries int
+	// BaseDelay is the delay before the first retry. It doubles with each further retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between retries, including delays requested by the server via Retry-After.
+	// If zero, the delay is not capped.
+	MaxDelay time.Duration
+}
+
+// delay returns how long to wait before the given retry, starting at 0.
+// A positive retryAfter, as requested by the server, takes precedence over the exponential backoff.
+// Either is capped at MaxDelay, if set.
+func (p RetryPolicy) delay(retry int, retryAfter time.Duration) time.Duration {
+	d := retryAfter
+	if d <= 0 {
+		d = p.BaseDelay
+		for i := 0; i < retry && (p.MaxDelay == 0 || d < p.MaxDelay); i++ {
+			if d > math.MaxInt64/2 {
+				// Saturate rather than overflow to a negative delay.
+				d = math.MaxInt64
+				break
+			}
+			d *= 2
+		}
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	return d
+}
+
+// do calls fn until it succeeds or fails with an error that is not a *retriableError,
+// at most MaxRetries+1 times. It stops waiting for the next attempt once ctx is done.
+func (p RetryPolicy) do(ctx context.Context, fn func() error) error {
+	for retry := 0; ; retry++ {
+		err := fn()
+		var rerr *retriableError
+		if err == nil || !errors.As(err, &rerr) || retry >= p.MaxRetries {
+			return err
+		}
+		timer := time.NewTimer(p.delay(retry, rerr.retryAfter))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// retriableError marks an error as transient, so that the request may be retried.
+type retriableError struct {
+	err error
+	// retryAfter is the delay requested by the server via the Retry-After header, if any.
+	retryAfter time.Duration
+}
+
+func (e *retriableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retriableError) Unwrap() error {
+	return e.err
+}
+
+func retriableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter parses the Retry-After header of resp, which holds either a number of seconds or an HTTP date.
+// It is only honored for the status codes 429 and 503 and returns zero if absent or invalid.
+func retryAfter(resp *http.Response) time.Duration {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0
+	}
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+		if int64(seconds) > math.MaxInt64/int64(time.Second) {
+			return math.MaxInt64
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
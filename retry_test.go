@@ -0,0 +1,136 @@
+package geozip_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ngrash/geozip"
+)
+
+func TestClient_RetryPolicy(t *testing.T) {
+	data, err := os.ReadFile("test_data/DE.zip")
+	if err != nil {
+		t.Fatal("read test data", err)
+	}
+	serve := serveBytes(data, "new_etag")
+	calls := 0
+	client := &geozip.Client{
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				calls++
+				switch calls {
+				case 1:
+					return &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{"Retry-After": []string{"0"}}}, nil
+				case 2:
+					return &http.Response{StatusCode: http.StatusBadGateway}, nil
+				}
+				return serve(r)
+			}),
+		},
+		RetryPolicy: geozip.RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond},
+	}
+
+	entries, modified, _, err := client.FetchCountry("DE", "")
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if !modified {
+		t.Error("modified = false, want true")
+	}
+	if got, want := len(entries), 16477; got != want {
+		t.Errorf("len(entries) = %v, want %v", got, want)
+	}
+	if got, want := calls, 3; got != want {
+		t.Errorf("transport called %d times, want %d", got, want)
+	}
+}
+
+func TestClient_RetryPolicy_Exhausted(t *testing.T) {
+	calls := 0
+	client := &geozip.Client{
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				calls++
+				return &http.Response{StatusCode: http.StatusServiceUnavailable, Status: "503 Service Unavailable"}, nil
+			}),
+		},
+		RetryPolicy: geozip.RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond},
+	}
+
+	_, _, _, err := client.FetchCountry("DE", "")
+	if err == nil {
+		t.Fatal("err = nil, want error")
+	}
+	if got, want := calls, 3; got != want {
+		t.Errorf("transport called %d times, want %d", got, want)
+	}
+}
+
+func TestClient_RetryPolicy_Disabled(t *testing.T) {
+	calls := 0
+	client := &geozip.Client{
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				calls++
+				return &http.Response{StatusCode: http.StatusServiceUnavailable}, nil
+			}),
+		},
+	}
+
+	if _, _, _, err := client.FetchCountry("DE", ""); err == nil {
+		t.Fatal("err = nil, want error")
+	}
+	if got, want := calls, 1; got != want {
+		t.Errorf("transport called %d times, want %d", got, want)
+	}
+}
+
+func TestClient_RetryPolicy_NotRetriable(t *testing.T) {
+	calls := 0
+	client := &geozip.Client{
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				calls++
+				return &http.Response{StatusCode: http.StatusNotFound}, nil
+			}),
+		},
+		RetryPolicy: geozip.RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond},
+	}
+
+	if _, _, _, err := client.FetchCountry("DE", ""); err == nil {
+		t.Fatal("err = nil, want error")
+	}
+	if got, want := calls, 1; got != want {
+		t.Errorf("transport called %d times, want %d", got, want)
+	}
+}
+
+func TestClient_RetryPolicy_MaxDelayCapsRetryAfter(t *testing.T) {
+	data := zipArchive(t, map[string]string{"DE.txt": "DE\t54668\tFerschweiler\n"})
+	serve := serveBytes(data, `"etag"`)
+	calls := 0
+	client := &geozip.Client{
+		HTTPClient: &http.Client{
+			Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				calls++
+				if calls == 1 {
+					return &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{"Retry-After": []string{"86400"}}}, nil
+				}
+				return serve(r)
+			}),
+		},
+		RetryPolicy: geozip.RetryPolicy{MaxRetries: 1, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := client.FetchCountryContext(ctx, "DE", ""); err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if got, want := calls, 2; got != want {
+		t.Errorf("transport called %d times, want %d", got, want)
+	}
+}
@@ -0,0 +1,35 @@
+package geozip
+
+import (
+	"errors"
+	"io"
+	"iter"
+)
+
+// errStopped signals that the consumer of a Seq stopped iterating.
+var errStopped = errors.New("iteration stopped")
+
+// Seq returns an iterator over the tab-separated postal code data read from r, for use with range-over-func:
+//
+//	for e, err := range geozip.Seq(file) {
+//	    if err != nil {
+//	        // Handle error
+//	    }
+//	    // Process e
+//	}
+//
+// Entries are parsed lazily like with ParseStream. If parsing fails, the iterator yields the zero Entry
+// along with the error and stops. Breaking out of the loop stops reading from r.
+func Seq(r io.Reader) iter.Seq2[Entry, error] {
+	return func(yield func(Entry, error) bool) {
+		err := ParseStream(r, func(e Entry) error {
+			if !yield(e, nil) {
+				return errStopped
+			}
+			return nil
+		})
+		if err != nil && err != errStopped {
+			yield(Entry{}, err)
+		}
+	}
+}
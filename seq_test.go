@@ -0,0 +1,60 @@
+package geozip_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ngrash/geozip"
+)
+
+func TestSeq(t *testing.T) {
+	const data = "DE\t54668\tFerschweiler\n" +
+		"DE\t56479\tNeustadt (Westerwald)\n"
+
+	var names []string
+	for e, err := range geozip.Seq(strings.NewReader(data)) {
+		if err != nil {
+			t.Fatalf("err = %v, want nil", err)
+		}
+		names = append(names, e[geozip.PlaceName])
+	}
+	if got, want := strings.Join(names, ","), "Ferschweiler,Neustadt (Westerwald)"; got != want {
+		t.Errorf("place names = %v, want %v", got, want)
+	}
+}
+
+func TestSeq_Error(t *testing.T) {
+	const data = "DE\t54668\tFerschweiler\n" +
+		"DE\t56479\tNeu\"stadt\n"
+
+	var entries, errs int
+	for _, err := range geozip.Seq(strings.NewReader(data)) {
+		if err != nil {
+			errs++
+			continue
+		}
+		entries++
+	}
+	if entries != 1 || errs != 1 {
+		t.Errorf("got %d entries and %d errors, want 1 and 1", entries, errs)
+	}
+}
+
+func TestSeq_Break(t *testing.T) {
+	const data = "DE\t54668\tFerschweiler\n" +
+		"DE\t56479\tNeustadt (Westerwald)\n" +
+		"DE\t56479\tNeu\"stadt\n"
+
+	// Continuing after a break would make the range loop panic.
+	calls := 0
+	for _, err := range geozip.Seq(strings.NewReader(data)) {
+		if err != nil {
+			t.Fatalf("err = %v, want nil", err)
+		}
+		calls++
+		break
+	}
+	if got, want := calls, 1; got != want {
+		t.Errorf("loop body ran %d times, want %d", got, want)
+	}
+}
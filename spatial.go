@@ -0,0 +1,174 @@
+package geozip
+
+import (
+	"math"
+	"sort"
+)
+
+// SpatialIndex answers geographic queries over entries in sublinear time, unlike the scans done by Index.
+// It is a k-d tree over the positions of the entries on the unit sphere, so queries are not affected by the
+// antimeridian or the poles. Entries without valid coordinates are excluded.
+//
+// A SpatialIndex is immutable and safe for concurrent use.
+type SpatialIndex struct {
+	// points is an implicit k-d tree: the root of every subslice is its middle element, which splits
+	// the remaining points along the axis given by the depth of the subslice.
+	points []spatialPoint
+}
+
+type spatialPoint struct {
+	pos      [3]float64
+	lat, lon float64
+	entry    Entry
+}
+
+// NewSpatialIndex builds a spatial index over the entries with valid coordinates. Building takes O(n log² n) time.
+func NewSpatialIndex(entries []Entry) *SpatialIndex {
+	points := make([]spatialPoint, 0, len(entries))
+	for _, e := range entries {
+		lat, lon, err := coordinates(e)
+		if err != nil {
+			continue
+		}
+		points = append(points, spatialPoint{pos: unitVector(lat, lon), lat: lat, lon: lon, entry: e})
+	}
+	buildTree(points, 0)
+	return &SpatialIndex{points: points}
+}
+
+// Len returns the number of entries in the index, i.e. those with valid coordinates.
+func (s *SpatialIndex) Len() int {
+	return len(s.points)
+}
+
+// Nearest returns the entry closest to the given coordinates along with its distance in meters.
+// It reports ok=false if the index is empty. Queries take O(log n) time on average.
+func (s *SpatialIndex) Nearest(lat, lon float64) (_ Entry, meters float64, ok bool) {
+	var best *spatialPoint
+	bestDist := math.Inf(1)
+	nearest(s.points, 0, unitVector(lat, lon), &best, &bestDist)
+	if best == nil {
+		return Entry{}, 0, false
+	}
+	return best.entry, DistanceLatLon(lat, lon, best.lat, best.lon), true
+}
+
+// Within returns all entries whose great-circle distance to the given coordinates is at most meters,
+// in no particular order. Use WithinRadius to get them sorted by distance.
+func (s *SpatialIndex) Within(lat, lon, meters float64) []Entry {
+	var in []Entry
+	s.within(lat, lon, meters, func(p *spatialPoint, _ float64) {
+		in = append(in, p.entry)
+	})
+	return in
+}
+
+// WithinRadius returns all entries whose great-circle distance to the given coordinates is at most meters,
+// sorted by distance in ascending order. Entries exactly on the boundary are included.
+//
+// For example, WithinRadius(lat, lon, 10000) lists all postal codes within 10 km.
+func (s *SpatialIndex) WithinRadius(lat, lon, meters float64) []Entry {
+	type match struct {
+		entry  Entry
+		meters float64
+	}
+	var matches []match
+	s.within(lat, lon, meters, func(p *spatialPoint, d float64) {
+		matches = append(matches, match{p.entry, d})
+	})
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].meters < matches[j].meters
+	})
+
+	in := make([]Entry, len(matches))
+	for i, m := range matches {
+		in[i] = m.entry
+	}
+	return in
+}
+
+// within calls fn for every point within meters of the given coordinates, along with the distance.
+func (s *SpatialIndex) within(lat, lon, meters float64, fn func(p *spatialPoint, meters float64)) {
+	if meters < 0 {
+		return
+	}
+	// The chord length between two points on the unit sphere grows monotonically with their great-circle distance.
+	// It is widened slightly, so that rounding errors cannot exclude points on the boundary. The candidates are
+	// then checked against the great-circle distance.
+	chord := 2*math.Sin(math.Min(meters/(2*earthRadius), math.Pi/2))*(1+1e-9) + 1e-12
+	inRange(s.points, 0, unitVector(lat, lon), chord, func(p *spatialPoint) {
+		if d := DistanceLatLon(lat, lon, p.lat, p.lon); d <= meters {
+			fn(p, d)
+		}
+	})
+}
+
+// unitVector returns the position of the given coordinates on the unit sphere.
+func unitVector(lat, lon float64) [3]float64 {
+	phi := lat * math.Pi / 180
+	lambda := lon * math.Pi / 180
+	return [3]float64{
+		math.Cos(phi) * math.Cos(lambda),
+		math.Cos(phi) * math.Sin(lambda),
+		math.Sin(phi),
+	}
+}
+
+func buildTree(points []spatialPoint, axis int) {
+	if len(points) <= 1 {
+		return
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].pos[axis] < points[j].pos[axis]
+	})
+	m := len(points) / 2
+	next := (axis + 1) % 3
+	buildTree(points[:m], next)
+	buildTree(points[m+1:], next)
+}
+
+func nearest(points []spatialPoint, axis int, q [3]float64, best **spatialPoint, bestDist *float64) {
+	if len(points) == 0 {
+		return
+	}
+	m := len(points) / 2
+	p := &points[m]
+	if d := squaredDistance(p.pos, q); d < *bestDist {
+		*best, *bestDist = p, d
+	}
+
+	diff := q[axis] - p.pos[axis]
+	near, far := points[:m], points[m+1:]
+	if diff > 0 {
+		near, far = far, near
+	}
+	next := (axis + 1) % 3
+	nearest(near, next, q, best, bestDist)
+	if diff*diff < *bestDist {
+		nearest(far, next, q, best, bestDist)
+	}
+}
+
+func inRange(points []spatialPoint, axis int, q [3]float64, r float64, fn func(*spatialPoint)) {
+	if len(points) == 0 {
+		return
+	}
+	m := len(points) / 2
+	p := &points[m]
+	if squaredDistance(p.pos, q) <= r*r {
+		fn(p)
+	}
+
+	next := (axis + 1) % 3
+	if q[axis]-r <= p.pos[axis] {
+		inRange(points[:m], next, q, r, fn)
+	}
+	if q[axis]+r >= p.pos[axis] {
+		inRange(points[m+1:], next, q, r, fn)
+	}
+}
+
+func squaredDistance(a, b [3]float64) float64 {
+	dx, dy, dz := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+	return dx*dx + dy*dy + dz*dz
+}
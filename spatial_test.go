@@ -0,0 +1,119 @@
+package geozip_test
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/ngrash/geozip"
+)
+
+func TestSpatialIndex_Nearest(t *testing.T) {
+	entries, err := geozip.ParseStrict(readTestData(t))
+	if err != nil {
+		t.Fatal("parse test data", err)
+	}
+	idx := geozip.NewIndex(entries)
+	spatial := geozip.NewSpatialIndex(entries)
+
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		lat, lon := 47+rnd.Float64()*8, 5+rnd.Float64()*11
+		_, wantMeters, _ := idx.Nearest(lat, lon)
+		_, gotMeters, ok := spatial.Nearest(lat, lon)
+		if !ok {
+			t.Fatal("ok = false, want true")
+		}
+		if gotMeters != wantMeters {
+			t.Errorf("Nearest(%v, %v): meters = %v, want %v", lat, lon, gotMeters, wantMeters)
+		}
+	}
+}
+
+func TestSpatialIndex_Nearest_Empty(t *testing.T) {
+	spatial := geozip.NewSpatialIndex([]geozip.Entry{{geozip.PostalCode: "12345"}})
+
+	if got, want := spatial.Len(), 0; got != want {
+		t.Errorf("Len() = %v, want %v", got, want)
+	}
+	if _, _, ok := spatial.Nearest(0, 0); ok {
+		t.Error("ok = true, want false")
+	}
+}
+
+func TestSpatialIndex_Within(t *testing.T) {
+	entries, err := geozip.ParseStrict(readTestData(t))
+	if err != nil {
+		t.Fatal("parse test data", err)
+	}
+	spatial := geozip.NewSpatialIndex(entries)
+
+	const lat, lon, meters = 50.6333, 8.0667, 10000
+	want := 0
+	for _, e := range entries {
+		if d, err := geozip.Distance(e, geozip.Entry{geozip.Latitude: "50.6333", geozip.Longitude: "8.0667"}); err == nil && d <= meters {
+			want++
+		}
+	}
+	if want == 0 {
+		t.Fatal("no entries within radius in test data")
+	}
+	if got := len(spatial.Within(lat, lon, meters)); got != want {
+		t.Errorf("len(Within()) = %v, want %v", got, want)
+	}
+}
+
+func TestSpatialIndex_Within_Antimeridian(t *testing.T) {
+	entries := []geozip.Entry{
+		{geozip.PlaceName: "west", geozip.Latitude: "-16.5", geozip.Longitude: "179.99"},
+		{geozip.PlaceName: "east", geozip.Latitude: "-16.5", geozip.Longitude: "-179.99"},
+	}
+	spatial := geozip.NewSpatialIndex(entries)
+
+	if got, want := len(spatial.Within(-16.5, 180, 5000)), 2; got != want {
+		t.Errorf("len(Within()) = %v, want %v", got, want)
+	}
+}
+
+func BenchmarkSpatialIndex_Nearest(b *testing.B) {
+	entries, err := geozip.ParseStrict(readTestData(b))
+	if err != nil {
+		b.Fatal("parse test data", err)
+	}
+	spatial := geozip.NewSpatialIndex(entries)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		spatial.Nearest(50.63, 8.07)
+	}
+}
+
+func BenchmarkIndex_Nearest(b *testing.B) {
+	entries, err := geozip.ParseStrict(readTestData(b))
+	if err != nil {
+		b.Fatal("parse test data", err)
+	}
+	idx := geozip.NewIndex(entries)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		idx.Nearest(50.63, 8.07)
+	}
+}
+
+func TestSpatialIndex_WithinRadius(t *testing.T) {
+	spatial := geozip.NewSpatialIndex(indexEntries)
+
+	// Rehe is the query point, Neustadt (Westerwald) is exactly on the boundary, and Ferschweiler is far away.
+	boundary := geozip.DistanceLatLon(50.6333, 8.0667, 50.6333, 8.0333)
+	in := spatial.WithinRadius(50.6333, 8.0667, boundary)
+	var names []string
+	for _, e := range in {
+		names = append(names, e[geozip.PlaceName])
+	}
+	if got, want := strings.Join(names, ","), "Rehe,Neustadt (Westerwald)"; got != want {
+		t.Errorf("WithinRadius() = %v, want %v", got, want)
+	}
+
+	if got := spatial.WithinRadius(50.6333, 8.0667, boundary-1); len(got) != 1 {
+		t.Errorf("len(WithinRadius(boundary-1)) = %v, want 1", len(got))
+	}
+}
@@ -0,0 +1,105 @@
+package geozip
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// sqlColumns are the column names used by InsertEntries in Field order.
+var sqlColumns = [numFields]string{
+	CountryCode: "country_code",
+	PostalCode:  "postal_code",
+	PlaceName:   "place_name",
+	AdminName1:  "admin_name1",
+	AdminCode1:  "admin_code1",
+	AdminName2:  "admin_name2",
+	AdminCode2:  "admin_code2",
+	AdminName3:  "admin_name3",
+	AdminCode3:  "admin_code3",
+	Latitude:    "latitude",
+	Longitude:   "longitude",
+	Accuracy:    "accuracy",
+}
+
+// maxInsertParams is the maximum number of parameters per statement executed by InsertEntries.
+// It is the lowest limit among common databases, that of SQLite before version 3.32.0.
+// SQL Server allows 2100 parameters, PostgreSQL 65535.
+const maxInsertParams = 999
+
+// insertBatchSize is the number of rows inserted per statement by InsertEntries, such that each statement
+// binds at most maxInsertParams parameters.
+const insertBatchSize = maxInsertParams / numFields
+
+// SQLColumns returns the column names used by InsertEntries in Field order, e.g. "postal_code" for PostalCode.
+func SQLColumns() []string {
+	return append([]string(nil), sqlColumns[:]...)
+}
+
+// SQLRows returns the fields of each entry as a row of values in Field order, matching SQLColumns.
+// This is useful for bulk loading mechanisms such as COPY.
+func SQLRows(entries []Entry) [][]any {
+	rows := make([][]any, len(entries))
+	for i, e := range entries {
+		row := make([]any, numFields)
+		for f, v := range e {
+			row[f] = v
+		}
+		rows[i] = row
+	}
+	return rows
+}
+
+// InsertEntries inserts entries into the given table using batched, parameterized INSERT statements
+// within a single transaction. The table must have the columns returned by SQLColumns, all of which
+// receive the raw string values of the entries.
+//
+// The statements use numbered placeholders ($1, $2, ...) as supported by PostgreSQL and SQLite.
+// The table name is inserted into the statements verbatim, so it must not come from untrusted input.
+func InsertEntries(ctx context.Context, db *sql.DB, table string, entries []Entry) (err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	for start := 0; start < len(entries); start += insertBatchSize {
+		batch := entries[start:min(start+insertBatchSize, len(entries))]
+		query, args := insertStatement(table, batch)
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("insert entries %d to %d: %w", start, start+len(batch)-1, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// insertStatement returns an INSERT statement for the given entries along with its arguments.
+func insertStatement(table string, entries []Entry) (string, []any) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "INSERT INTO %s (%s) VALUES ", table, strings.Join(sqlColumns[:], ", "))
+	args := make([]any, 0, len(entries)*numFields)
+	for i, e := range entries {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteByte('(')
+		for f, v := range e {
+			if f > 0 {
+				b.WriteString(", ")
+			}
+			args = append(args, v)
+			fmt.Fprintf(&b, "$%d", len(args))
+		}
+		b.WriteByte(')')
+	}
+	return b.String(), args
+}
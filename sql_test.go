@@ -0,0 +1,165 @@
+package geozip_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ngrash/geozip"
+)
+
+// recordingDriver is a database/sql driver that records executed statements.
+type recordingDriver struct {
+	mu        sync.Mutex
+	queries   []string
+	args      [][]driver.Value
+	committed bool
+	failExec  bool
+}
+
+func (d *recordingDriver) Open(string) (driver.Conn, error) { return recordingConn{d}, nil }
+
+type recordingConn struct{ d *recordingDriver }
+
+func (c recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return recordingStmt{d: c.d, query: query}, nil
+}
+func (c recordingConn) Close() error              { return nil }
+func (c recordingConn) Begin() (driver.Tx, error) { return recordingTx{c.d}, nil }
+
+type recordingTx struct{ d *recordingDriver }
+
+func (tx recordingTx) Commit() error {
+	tx.d.mu.Lock()
+	defer tx.d.mu.Unlock()
+	tx.d.committed = true
+	return nil
+}
+func (tx recordingTx) Rollback() error { return nil }
+
+type recordingStmt struct {
+	d     *recordingDriver
+	query string
+}
+
+func (s recordingStmt) Close() error  { return nil }
+func (s recordingStmt) NumInput() int { return -1 }
+func (s recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	if s.d.failExec {
+		return nil, errors.New("exec failed")
+	}
+	s.d.queries = append(s.d.queries, s.query)
+	s.d.args = append(s.d.args, args)
+	return driver.RowsAffected(1), nil
+}
+func (s recordingStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+// currentDriver is the recordingDriver used by newly opened connections.
+// Drivers can only be registered once, so the registered switchDriver delegates to it.
+var currentDriver struct {
+	sync.Mutex
+	d *recordingDriver
+}
+
+type switchDriver struct{}
+
+func (switchDriver) Open(name string) (driver.Conn, error) {
+	currentDriver.Lock()
+	defer currentDriver.Unlock()
+	return currentDriver.d.Open(name)
+}
+
+func init() {
+	sql.Register("geozip-recording", switchDriver{})
+}
+
+func openRecordingDB(t *testing.T, d *recordingDriver) *sql.DB {
+	t.Helper()
+	currentDriver.Lock()
+	currentDriver.d = d
+	currentDriver.Unlock()
+	db, err := sql.Open("geozip-recording", "")
+	if err != nil {
+		t.Fatal("open database", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestInsertEntries(t *testing.T) {
+	d := &recordingDriver{}
+	db := openRecordingDB(t, d)
+
+	entries := make([]geozip.Entry, 1500)
+	for i := range entries {
+		entries[i] = geozip.Entry{geozip.CountryCode: "DE", geozip.PostalCode: "54668", geozip.PlaceName: "Ferschweiler"}
+	}
+	if err := geozip.InsertEntries(context.Background(), db, "postal_codes", entries); err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	// Each statement binds at most 999 parameters, i.e. 83 rows of 12 fields, so the last of 19 has 6 rows.
+	if got, want := len(d.queries), 19; got != want {
+		t.Fatalf("%d statements executed, want %d", got, want)
+	}
+	const prefix = "INSERT INTO postal_codes (country_code, postal_code, place_name, admin_name1, admin_code1, " +
+		"admin_name2, admin_code2, admin_name3, admin_code3, latitude, longitude, accuracy) VALUES ($1, $2, $3,"
+	if !strings.HasPrefix(d.queries[0], prefix) {
+		t.Errorf("query = %.200q, want prefix %q", d.queries[0], prefix)
+	}
+	last := d.queries[len(d.queries)-1]
+	if !strings.HasSuffix(last, "$72)") {
+		t.Errorf("query = ...%q, want suffix $72)", last[len(last)-20:])
+	}
+	for i, args := range d.args {
+		if len(args) > 999 {
+			t.Errorf("batch %d has %d args, want at most 999", i, len(args))
+		}
+	}
+	if got, want := len(d.args[0]), 996; got != want {
+		t.Errorf("first batch has %d args, want %d", got, want)
+	}
+	if got, want := d.args[18][2], driver.Value("Ferschweiler"); got != want {
+		t.Errorf("args[18][2] = %v, want %v", got, want)
+	}
+	if !d.committed {
+		t.Error("transaction not committed")
+	}
+}
+
+func TestInsertEntries_Error(t *testing.T) {
+	d := &recordingDriver{failExec: true}
+	db := openRecordingDB(t, d)
+
+	err := geozip.InsertEntries(context.Background(), db, "postal_codes", []geozip.Entry{{}})
+	if err == nil {
+		t.Fatal("err = nil, want error")
+	}
+	if d.committed {
+		t.Error("transaction committed, want rollback")
+	}
+}
+
+func TestSQLRows(t *testing.T) {
+	rows := geozip.SQLRows([]geozip.Entry{{geozip.PostalCode: "54668", geozip.Accuracy: "4"}})
+	if got, want := len(rows), 1; got != want {
+		t.Fatalf("len(rows) = %v, want %v", got, want)
+	}
+	if got, want := len(rows[0]), len(geozip.SQLColumns()); got != want {
+		t.Fatalf("len(rows[0]) = %v, want %v", got, want)
+	}
+	if got, want := rows[0][geozip.PostalCode], any("54668"); got != want {
+		t.Errorf("rows[0][PostalCode] = %v, want %v", got, want)
+	}
+	if got, want := geozip.SQLColumns()[geozip.Accuracy], "accuracy"; got != want {
+		t.Errorf("SQLColumns()[Accuracy] = %v, want %v", got, want)
+	}
+}
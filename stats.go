@@ -0,0 +1,49 @@
+package geozip
+
+// DatasetStats summarizes a set of entries, as returned by Stats.
+type DatasetStats struct {
+	// Entries is the total number of entries.
+	Entries int
+	// PostalCodes is the number of distinct postal codes.
+	PostalCodes int
+	// PlaceNames is the number of distinct place names.
+	PlaceNames int
+	// MissingCoordinates is the number of entries without valid coordinates.
+	MissingCoordinates int
+
+	// MinLatitude, MinLongitude, MaxLatitude and MaxLongitude describe the bounding box of all valid coordinates.
+	// They are zero if no entry has valid coordinates, i.e. if MissingCoordinates equals Entries.
+	MinLatitude, MinLongitude, MaxLatitude, MaxLongitude float64
+}
+
+// Stats computes aggregate information about entries.
+// Entries without valid coordinates are counted in MissingCoordinates and excluded from the bounding box.
+func Stats(entries []Entry) DatasetStats {
+	s := DatasetStats{Entries: len(entries)}
+	postalCodes := make(map[string]struct{})
+	placeNames := make(map[string]struct{})
+	located := false
+	for _, e := range entries {
+		postalCodes[e[PostalCode]] = struct{}{}
+		placeNames[e[PlaceName]] = struct{}{}
+
+		lat, lon, err := coordinates(e)
+		if err != nil {
+			s.MissingCoordinates++
+			continue
+		}
+		if !located {
+			s.MinLatitude, s.MaxLatitude = lat, lat
+			s.MinLongitude, s.MaxLongitude = lon, lon
+			located = true
+			continue
+		}
+		s.MinLatitude = min(s.MinLatitude, lat)
+		s.MaxLatitude = max(s.MaxLatitude, lat)
+		s.MinLongitude = min(s.MinLongitude, lon)
+		s.MaxLongitude = max(s.MaxLongitude, lon)
+	}
+	s.PostalCodes = len(postalCodes)
+	s.PlaceNames = len(placeNames)
+	return s
+}
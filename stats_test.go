@@ -0,0 +1,39 @@
+package geozip_test
+
+import (
+	"testing"
+
+	"github.com/ngrash/geozip"
+)
+
+func TestStats(t *testing.T) {
+	entries := []geozip.Entry{
+		{geozip.PostalCode: "54668", geozip.PlaceName: "Ferschweiler", geozip.Latitude: "49.8667", geozip.Longitude: "6.4"},
+		{geozip.PostalCode: "56479", geozip.PlaceName: "Neustadt (Westerwald)", geozip.Latitude: "50.6", geozip.Longitude: "7.9"},
+		{geozip.PostalCode: "56479", geozip.PlaceName: "Rehe", geozip.Latitude: "50.6333", geozip.Longitude: "8.1167"},
+		{geozip.PostalCode: "99999", geozip.PlaceName: "Rehe"},
+	}
+
+	got := geozip.Stats(entries)
+	want := geozip.DatasetStats{
+		Entries:            4,
+		PostalCodes:        3,
+		PlaceNames:         3,
+		MissingCoordinates: 1,
+		MinLatitude:        49.8667,
+		MinLongitude:       6.4,
+		MaxLatitude:        50.6333,
+		MaxLongitude:       8.1167,
+	}
+	if got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestStats_NoCoordinates(t *testing.T) {
+	got := geozip.Stats([]geozip.Entry{{geozip.PostalCode: "99999"}})
+	want := geozip.DatasetStats{Entries: 1, PostalCodes: 1, PlaceNames: 1, MissingCoordinates: 1}
+	if got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
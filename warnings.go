@@ -0,0 +1,92 @@
+package geozip
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Warning describes a non-fatal anomaly found by ParseWithWarnings.
+type Warning struct {
+	// Row is the 1-based number of the row the anomaly was found in.
+	Row int
+	// Field is the affected field.
+	Field Field
+	// Message describes the anomaly.
+	Message string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("row %d: %v: %s", w.Row, w.Field, w.Message)
+}
+
+// ParseWithWarnings parses tab-separated postal code data like ParseStrict, but tolerates rows with fewer than
+// 12 fields and reports them, along with other data quality issues, as warnings instead of failing:
+//
+//   - rows with missing fields
+//   - blank postal codes
+//   - blank or invalid coordinates
+//   - accuracies that are invalid or outside the documented range from 1 to 6
+//
+// Rows with more than 12 fields are still rejected, as they do not fit into an Entry.
+func ParseWithWarnings(data []byte) ([]Entry, []Warning, error) {
+	reader := newCSVReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	es := make([]Entry, 0)
+	var warnings []Warning
+	for row := 1; ; row++ {
+		columns, err := readRecord(reader)
+		if err == io.EOF {
+			return es, warnings, nil
+		}
+		if err != nil {
+			return nil, nil, withLineContext(err, openBytes(data))
+		}
+		if got, limit := len(columns), numFields; got > limit {
+			return nil, nil, fmt.Errorf("row %d has %d fields, want at most %d: %w", row, got, limit, ErrTooManyFields)
+		}
+		if len(columns) < numFields {
+			warnings = append(warnings, Warning{row, Field(len(columns)),
+				fmt.Sprintf("row has %d fields, want %d", len(columns), numFields)})
+		}
+		e := newEntry(columns)
+		warnings = append(warnings, checkEntry(row, e)...)
+		es = append(es, e)
+	}
+}
+
+// checkEntry returns warnings about the fields of e, the entry parsed from the given row.
+func checkEntry(row int, e Entry) []Warning {
+	var warnings []Warning
+	warn := func(f Field, format string, args ...any) {
+		warnings = append(warnings, Warning{row, f, fmt.Sprintf(format, args...)})
+	}
+
+	if e[PostalCode] == "" {
+		warn(PostalCode, "blank postal code")
+	}
+	for _, c := range []struct {
+		f     Field
+		limit float64
+	}{{Latitude, 90}, {Longitude, 180}} {
+		if e[c.f] == "" {
+			warn(c.f, "blank coordinate")
+			continue
+		}
+		v, err := strconv.ParseFloat(e[c.f], 64)
+		if err != nil {
+			warn(c.f, "invalid coordinate %q", e[c.f])
+		} else if v < -c.limit || v > c.limit {
+			warn(c.f, "coordinate %v out of range [%v, %v]", v, -c.limit, c.limit)
+		}
+	}
+	if e[Accuracy] != "" {
+		if a, ok := e.Accuracy(); !ok {
+			warn(Accuracy, "invalid accuracy %q", e[Accuracy])
+		} else if a < AccuracyEstimated || a > AccuracyCentroid {
+			warn(Accuracy, "accuracy %d out of range [%d, %d]", a, AccuracyEstimated, AccuracyCentroid)
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,47 @@
+package geozip_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ngrash/geozip"
+)
+
+func TestParseWithWarnings(t *testing.T) {
+	const data = "DE\t54668\tFerschweiler\tRheinland-Pfalz\tRP\t\t00\tEifelkreis Bitburg-Prüm\t07232\t49.8667\t6.4\t4\n" +
+		"DE\t56479\tNeustadt (Westerwald)\n" +
+		"DE\t\tRehe\tRheinland-Pfalz\tRP\t\t00\tWesterwaldkreis\t07143\t95\teast\t9\n"
+
+	entries, warnings, err := geozip.ParseWithWarnings([]byte(data))
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if got, want := len(entries), 3; got != want {
+		t.Errorf("len(entries) = %v, want %v", got, want)
+	}
+
+	var got []string
+	for _, w := range warnings {
+		got = append(got, w.String())
+	}
+	want := []string{
+		"row 2: AdminName1: row has 3 fields, want 12",
+		"row 2: Latitude: blank coordinate",
+		"row 2: Longitude: blank coordinate",
+		"row 3: PostalCode: blank postal code",
+		"row 3: Latitude: coordinate 95 out of range [-90, 90]",
+		`row 3: Longitude: invalid coordinate "east"`,
+		"row 3: Accuracy: accuracy 9 out of range [1, 6]",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("warnings =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestParseWithWarnings_TooManyFields(t *testing.T) {
+	data := strings.Repeat("x\t", 12) + "x\n"
+
+	if _, _, err := geozip.ParseWithWarnings([]byte(data)); err == nil {
+		t.Error("err = nil, want error for 13 fields")
+	}
+}